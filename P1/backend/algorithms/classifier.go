@@ -0,0 +1,172 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Classifier is the common API of every model in this package.
+type Classifier interface {
+	Fit(X *mat.Dense, y []int) error
+	Predict(X *mat.Dense) ([]int, error)
+	PredictProba(X *mat.Dense) (*mat.Dense, error)
+	SaveToFile(path string) error
+	// ModelType is the name stored in the artifact ("model_type").
+	ModelType() string
+}
+
+// Model types stored in the "model_type" field of the artifact.
+const (
+	ModelSoftmax      = "softmax"
+	ModelDecisionTree = "decision_tree"
+	ModelNaiveBayes   = "gaussian_nb"
+	ModelKNN          = "knn"
+	ModelMLP          = "mlp"
+	ModelEnsemble     = "ensemble"
+)
+
+// ModelTypes lists the classifiers that NewClassifier can build.
+var ModelTypes = []string{ModelSoftmax, ModelDecisionTree, ModelNaiveBayes, ModelKNN, ModelMLP}
+
+// NewClassifier returns an untrained classifier of the given type with its
+// default hyperparameters.
+func NewClassifier(modelType string) (Classifier, error) {
+	switch modelType {
+	case "", ModelSoftmax:
+		return NewSoftmaxRegression(0.1, 2000, 1e-3), nil
+	case ModelDecisionTree:
+		return NewDecisionTree(5, 2), nil
+	case ModelNaiveBayes:
+		return NewGaussianNB(), nil
+	case ModelKNN:
+		return NewKNN(5), nil
+	case ModelMLP:
+		return NewMLP([]int{16}, 0.05, 2000, 1e-3), nil
+	default:
+		return nil, fmt.Errorf("unknown model type %q: %w", modelType, ErrInvalidParam)
+	}
+}
+
+// artifactEncoder is implemented by every classifier: it returns the file
+// struct that SaveToFile writes, so models can be embedded in other artifacts.
+type artifactEncoder interface {
+	artifact() (any, error)
+}
+
+// LoadClassifier loads any model artifact, choosing the implementation from
+// its "model_type" field. Artifacts without that field are softmax models;
+// binary artifacts (ExportModel with FormatBinary) are also accepted. If the
+// artifact has a checksum sidecar it must match (see
+// LoadVerifiedClassifier).
+func LoadClassifier(path string) (Classifier, error) {
+	return LoadVerifiedClassifier(path, nil)
+}
+
+// decodeClassifier is LoadClassifier on the bytes of an artifact: JSON, or
+// the binary format of SoftmaxRegression.MarshalBinary.
+func decodeClassifier(bytes []byte) (Classifier, error) {
+	if isBinaryArtifact(bytes) {
+		return decodeSoftmaxBinary(bytes)
+	}
+	var header struct {
+		ModelType string `json:"model_type"`
+	}
+	if err := json.Unmarshal(bytes, &header); err != nil {
+		return nil, err
+	}
+
+	switch header.ModelType {
+	case "", ModelSoftmax:
+		return decodeSoftmaxRegression(bytes)
+	case ModelDecisionTree:
+		return decodeDecisionTree(bytes)
+	case ModelNaiveBayes:
+		return decodeGaussianNB(bytes)
+	case ModelKNN:
+		return decodeKNN(bytes)
+	case ModelMLP:
+		return decodeMLP(bytes)
+	case ModelEnsemble:
+		return decodeEnsemble(bytes)
+	default:
+		return nil, fmt.Errorf("LoadClassifier: unknown model type %q", header.ModelType)
+	}
+}
+
+// Accuracy computes the fraction of correct predictions of any classifier.
+func Accuracy(c Classifier, X *mat.Dense, y []int) (float64, error) {
+	yPred, err := c.Predict(X)
+	if err != nil {
+		return 0, err
+	}
+	if len(yPred) != len(y) {
+		return 0, fmt.Errorf("Accuracy: %d predictions and %d labels: %w", len(yPred), len(y), ErrDimensionMismatch)
+	}
+	correct := 0
+	for i := range y {
+		if yPred[i] == y[i] {
+			correct++
+		}
+	}
+	return float64(correct) / float64(len(y)), nil
+}
+
+// argmaxRows returns the argmax of every row of probs.
+func argmaxRows(probs *mat.Dense) []int {
+	r, _ := probs.Dims()
+	out := make([]int, r)
+	for i := 0; i < r; i++ {
+		out[i] = argmaxRow(probs.RawRowView(i))
+	}
+	return out
+}
+
+// countClasses returns max(y) + 1.
+func countClasses(y []int) int {
+	nClasses := 0
+	for _, yi := range y {
+		if yi+1 > nClasses {
+			nClasses = yi + 1
+		}
+	}
+	return nClasses
+}
+
+// writeModelJSON writes any model file struct as indented JSON.
+func writeModelJSON(path string, v any) error {
+	bytes, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return saveArtifact(path, bytes)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so readers never see a half-written artifact.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op after a successful rename
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
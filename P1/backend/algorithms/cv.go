@@ -0,0 +1,124 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// HyperParams groups the hyperparameters of SoftmaxRegression.
+type HyperParams struct {
+	Lr        float64 `json:"lr"`
+	NIter     int     `json:"n_iter"`
+	RegLambda float64 `json:"reg_lambda"`
+}
+
+// NewModel creates an untrained model with these hyperparameters.
+func (h HyperParams) NewModel(seed int64) *SoftmaxRegression {
+	m := NewSoftmaxRegression(h.Lr, h.NIter, h.RegLambda)
+	m.Seed = seed
+	return m
+}
+
+// StratifiedKFold splits the sample indices in k folds keeping the class
+// proportions of y in every fold. The split is reproducible for a seed.
+func StratifiedKFold(y []int, k int, seed int64) ([][]int, error) {
+	if k < 2 {
+		return nil, fmt.Errorf("StratifiedKFold: k must be >= 2, got %d", k)
+	}
+	if k > len(y) {
+		return nil, fmt.Errorf("StratifiedKFold: k=%d greater than number of samples %d: %w", k, len(y), ErrDimensionMismatch)
+	}
+
+	byClass := map[int][]int{}
+	maxClass := 0
+	for i, yi := range y {
+		byClass[yi] = append(byClass[yi], i)
+		if yi > maxClass {
+			maxClass = yi
+		}
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	folds := make([][]int, k)
+	next := 0
+	// recorremos las clases en orden para que el resultado sea determinista
+	for c := 0; c <= maxClass; c++ {
+		idx := byClass[c]
+		rng.Shuffle(len(idx), func(i, j int) { idx[i], idx[j] = idx[j], idx[i] })
+		for _, i := range idx {
+			folds[next] = append(folds[next], i)
+			next = (next + 1) % k
+		}
+	}
+	return folds, nil
+}
+
+// subset returns the rows of X and y listed in idx.
+func subset(X *mat.Dense, y []int, idx []int) (*mat.Dense, []int) {
+	_, nFeatures := X.Dims()
+	data := make([]float64, 0, len(idx)*nFeatures)
+	ySub := make([]int, 0, len(idx))
+	for _, i := range idx {
+		data = append(data, X.RawRowView(i)...)
+		ySub = append(ySub, y[i])
+	}
+	return mat.NewDense(len(idx), nFeatures, data), ySub
+}
+
+// CrossValidate trains one model per fold with hp and returns the mean and
+// standard deviation of the validation accuracy.
+func CrossValidate(X *mat.Dense, y []int, hp HyperParams, k int, seed int64) (float64, float64, error) {
+	return CrossValidateClassifier(X, y, func() Classifier { return hp.NewModel(seed) }, k, seed)
+}
+
+// CrossValidateClassifier is CrossValidate for any classifier: newModel must
+// return a fresh untrained model every time it is called.
+func CrossValidateClassifier(X *mat.Dense, y []int, newModel func() Classifier, k int, seed int64) (float64, float64, error) {
+	nSamples, _ := X.Dims()
+	if err := validateLabels("CrossValidate", y, nSamples, 0); err != nil {
+		return 0, 0, err
+	}
+	folds, err := StratifiedKFold(y, k, seed)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	scores := make([]float64, 0, k)
+	for f := range folds {
+		var trainIdx []int
+		for g, fold := range folds {
+			if g != f {
+				trainIdx = append(trainIdx, fold...)
+			}
+		}
+		Xtr, ytr := subset(X, y, trainIdx)
+		Xval, yval := subset(X, y, folds[f])
+
+		model := newModel()
+		if err := model.Fit(Xtr, ytr); err != nil {
+			return 0, 0, err
+		}
+		acc, err := Accuracy(model, Xval, yval)
+		if err != nil {
+			return 0, 0, err
+		}
+		scores = append(scores, acc)
+	}
+
+	mean := 0.0
+	for _, s := range scores {
+		mean += s
+	}
+	mean /= float64(len(scores))
+
+	variance := 0.0
+	for _, s := range scores {
+		variance += (s - mean) * (s - mean)
+	}
+	variance /= float64(len(scores))
+
+	return mean, math.Sqrt(variance), nil
+}
@@ -0,0 +1,412 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat/distuv"
+)
+
+// ===== Training-set statistics =====
+
+// FeatureStats summarizes one column of the training set. The column is cut
+// into bins at its deciles: bin i holds the values in (Cuts[i-1], Cuts[i]],
+// the last bin the values above every cut. Repeated deciles are merged, so
+// a 0/1 column has at most three bins.
+type FeatureStats struct {
+	Name        string    `json:"name,omitempty"`
+	Mean        float64   `json:"mean"`
+	Std         float64   `json:"std"`
+	Min         float64   `json:"min"`
+	Max         float64   `json:"max"`
+	Cuts        []float64 `json:"cuts"`
+	Proportions []float64 `json:"proportions"` // share of training rows per bin, len(Cuts)+1
+}
+
+// TrainingStats is the reference that DriftMonitor compares live inputs
+// against. Fit stores it in the model, and the model artifact carries it.
+type TrainingStats struct {
+	NSamples         int            `json:"n_samples"`
+	Features         []FeatureStats `json:"features"`
+	ClassProportions []float64      `json:"class_proportions"`
+}
+
+// StatsCarrier is implemented by classifiers that keep the statistics of
+// their training set. TrainingStats returns nil for models trained or saved
+// before the statistics existed.
+type StatsCarrier interface {
+	TrainingStats() *TrainingStats
+}
+
+// NewTrainingStats computes the statistics of X and y. names may be nil.
+func NewTrainingStats(X *mat.Dense, y []int, names []string) *TrainingStats {
+	nSamples, nFeatures := X.Dims()
+	stats := &TrainingStats{NSamples: nSamples}
+
+	column := make([]float64, nSamples)
+	for j := 0; j < nFeatures; j++ {
+		mat.Col(column, j, X)
+		sorted := append([]float64(nil), column...)
+		sort.Float64s(sorted)
+
+		f := FeatureStats{Min: sorted[0], Max: sorted[nSamples-1]}
+		if j < len(names) {
+			f.Name = names[j]
+		}
+		for _, v := range column {
+			f.Mean += v
+		}
+		f.Mean /= float64(nSamples)
+		for _, v := range column {
+			f.Std += (v - f.Mean) * (v - f.Mean)
+		}
+		f.Std = math.Sqrt(f.Std / float64(nSamples))
+
+		for q := 1; q < 10; q++ {
+			cut := sorted[q*(nSamples-1)/10]
+			if len(f.Cuts) == 0 || cut > f.Cuts[len(f.Cuts)-1] {
+				f.Cuts = append(f.Cuts, cut)
+			}
+		}
+		counts := make([]int, len(f.Cuts)+1)
+		for _, v := range column {
+			counts[binOf(f.Cuts, v)]++
+		}
+		f.Proportions = proportions(counts)
+		stats.Features = append(stats.Features, f)
+	}
+
+	classCounts := make([]int, countClasses(y))
+	for _, yi := range y {
+		classCounts[yi]++
+	}
+	stats.ClassProportions = proportions(classCounts)
+	return stats
+}
+
+// binOf returns the bin of v for the cuts of a FeatureStats.
+func binOf(cuts []float64, v float64) int {
+	return sort.SearchFloat64s(cuts, v)
+}
+
+func proportions(counts []int) []float64 {
+	total := 0
+	for _, c := range counts {
+		total += c
+	}
+	out := make([]float64, len(counts))
+	if total == 0 {
+		return out
+	}
+	for i, c := range counts {
+		out[i] = float64(c) / float64(total)
+	}
+	return out
+}
+
+// check validates the dimensions of statistics read from an artifact; nil
+// statistics (older artifacts) are valid.
+func (s *TrainingStats) check(op string, nFeatures int) error {
+	if s == nil {
+		return nil
+	}
+	if len(s.Features) != nFeatures {
+		return fmt.Errorf("%s: training_stats has %d features, model has %d", op, len(s.Features), nFeatures)
+	}
+	for j, f := range s.Features {
+		if len(f.Proportions) != len(f.Cuts)+1 || !sort.Float64sAreSorted(f.Cuts) {
+			return fmt.Errorf("%s: training_stats feature %d has invalid bins", op, j)
+		}
+	}
+	return nil
+}
+
+// ===== Drift tests =====
+
+// psiEpsilon replaces empty bins in PSI, where ln(0) is undefined.
+const psiEpsilon = 1e-4
+
+// PSI is the population stability index of actual against expected, two
+// distributions over the same bins: sum (a - e) * ln(a / e).
+func PSI(expected, actual []float64) float64 {
+	psi := 0.0
+	for i := range expected {
+		e := math.Max(expected[i], psiEpsilon)
+		a := psiEpsilon
+		if i < len(actual) {
+			a = math.Max(actual[i], psiEpsilon)
+		}
+		psi += (a - e) * math.Log(a/e)
+	}
+	return psi
+}
+
+// psiPValue is the probability of a PSI at least psi between two samples
+// of sizes n and m of the same distribution: PSI * n*m/(n+m) is
+// approximately chi-squared with (non-empty bins - 1) degrees of freedom.
+// With small samples a large PSI is often just noise.
+func psiPValue(psi float64, expected, actual []float64, n, m int) float64 {
+	bins := 0
+	for i := range expected {
+		if expected[i] > 0 || (i < len(actual) && actual[i] > 0) {
+			bins++
+		}
+	}
+	if bins < 2 || n == 0 || m == 0 {
+		return 1
+	}
+	ne := float64(n) * float64(m) / float64(n+m)
+	return distuv.ChiSquared{K: float64(bins - 1)}.Survival(psi * ne)
+}
+
+// binnedKS returns the two-sample Kolmogorov-Smirnov statistic of two
+// distributions over the same bins: the largest gap between their CDFs at
+// the bin edges. It is never above the KS of the raw samples.
+func binnedKS(expected, actual []float64) float64 {
+	d, cumE, cumA := 0.0, 0.0, 0.0
+	for i := range expected {
+		cumE += expected[i]
+		cumA += actual[i]
+		d = math.Max(d, math.Abs(cumE-cumA))
+	}
+	return d
+}
+
+// ksPValue is the asymptotic p-value of a two-sample KS statistic d with
+// sample sizes n and m (Numerical Recipes, probks).
+func ksPValue(d float64, n, m int) float64 {
+	if n == 0 || m == 0 {
+		return 1
+	}
+	ne := float64(n) * float64(m) / float64(n+m)
+	sq := math.Sqrt(ne)
+	lambda := (sq + 0.12 + 0.11/sq) * d
+	if lambda < 1e-3 {
+		return 1
+	}
+	sum, sign := 0.0, 1.0
+	for k := 1; k <= 100; k++ {
+		term := sign * 2 * math.Exp(-2*float64(k*k)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-10 {
+			break
+		}
+		sign = -sign
+	}
+	return math.Min(math.Max(sum, 0), 1)
+}
+
+// ===== Drift monitor =====
+
+// Drift levels of a feature or of the predicted classes.
+const (
+	DriftOK      = "ok"
+	DriftWarning = "warning"
+	DriftAlert   = "alert"
+)
+
+// DriftThresholds decide the level of each test. PSI levels are only raised
+// when the PSI is also significant at PValue.
+type DriftThresholds struct {
+	MinSamples int     // no levels are raised below this many observations
+	PSIWarning float64 // PSI >= PSIWarning is a warning
+	PSIAlert   float64 // PSI >= PSIAlert is an alert
+	PValue     float64 // significance level of the PSI and KS tests
+	OutOfRange float64 // a share of values outside the training range above this is a warning
+}
+
+// DefaultDriftThresholds returns the usual PSI rule of thumb (0.1 / 0.25),
+// 1% tests and 30 observations.
+func DefaultDriftThresholds() DriftThresholds {
+	return DriftThresholds{MinSamples: 30, PSIWarning: 0.1, PSIAlert: 0.25, PValue: 0.01, OutOfRange: 0.05}
+}
+
+// FeatureDrift compares the live values of one feature with training.
+type FeatureDrift struct {
+	Index      int     `json:"index"`
+	Feature    string  `json:"feature,omitempty"`
+	PSI        float64 `json:"psi"`
+	PSIPValue  float64 `json:"psi_p_value"`
+	KS         float64 `json:"ks"`
+	KSPValue   float64 `json:"ks_p_value"`
+	TrainMean  float64 `json:"train_mean"`
+	LiveMean   float64 `json:"live_mean"`
+	OutOfRange float64 `json:"out_of_range"` // share of live values outside the training [min, max]
+	Level      string  `json:"level"`
+}
+
+// DriftReport is the state of a DriftMonitor.
+type DriftReport struct {
+	NObserved             int            `json:"n_observed"`
+	NTraining             int            `json:"n_training"`
+	Sufficient            bool           `json:"sufficient"` // NObserved >= MinSamples
+	Features              []FeatureDrift `json:"features"`
+	ClassPSI              float64        `json:"class_psi"`
+	ClassPSIPValue        float64        `json:"class_psi_p_value"`
+	ClassProportions      []float64      `json:"class_proportions"`
+	TrainClassProportions []float64      `json:"train_class_proportions"`
+	ClassLevel            string         `json:"class_level"`
+	Level                 string         `json:"level"` // worst level of the report
+	Alerts                []string       `json:"alerts"`
+}
+
+// DriftMonitor accumulates the inputs and predicted classes of a served
+// model and compares them with its TrainingStats. It keeps counts per bin,
+// not the inputs. It is safe for concurrent use.
+type DriftMonitor struct {
+	Reference *TrainingStats
+
+	mu          sync.Mutex
+	n           int
+	binCounts   [][]int
+	sums        []float64
+	outOfRange  []int
+	classCounts []int
+}
+
+// NewDriftMonitor creates an empty monitor for ref.
+func NewDriftMonitor(ref *TrainingStats) *DriftMonitor {
+	m := &DriftMonitor{Reference: ref}
+	m.Reset()
+	return m
+}
+
+// Reset discards every observation.
+func (m *DriftMonitor) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	nFeatures := len(m.Reference.Features)
+	m.n = 0
+	m.binCounts = make([][]int, nFeatures)
+	for j, f := range m.Reference.Features {
+		m.binCounts[j] = make([]int, len(f.Cuts)+1)
+	}
+	m.sums = make([]float64, nFeatures)
+	m.outOfRange = make([]int, nFeatures)
+	m.classCounts = make([]int, len(m.Reference.ClassProportions))
+}
+
+// Observe records the rows of X and their predicted classes.
+func (m *DriftMonitor) Observe(X *mat.Dense, classes []int) error {
+	if err := validateMatrix("DriftMonitor.Observe", X, len(m.Reference.Features)); err != nil {
+		return err
+	}
+	nSamples, _ := X.Dims()
+	if len(classes) != nSamples {
+		return fmt.Errorf("DriftMonitor.Observe: %d rows and %d classes: %w", nSamples, len(classes), ErrDimensionMismatch)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := 0; i < nSamples; i++ {
+		for j, v := range X.RawRowView(i) {
+			f := m.Reference.Features[j]
+			m.binCounts[j][binOf(f.Cuts, v)]++
+			m.sums[j] += v
+			if v < f.Min || v > f.Max {
+				m.outOfRange[j]++
+			}
+		}
+		c := classes[i]
+		if c < 0 {
+			continue
+		}
+		for c >= len(m.classCounts) {
+			m.classCounts = append(m.classCounts, 0)
+		}
+		m.classCounts[c]++
+	}
+	m.n += nSamples
+	return nil
+}
+
+// Report runs the drift tests on the observations so far.
+func (m *DriftMonitor) Report(th DriftThresholds) DriftReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ref := m.Reference
+	report := DriftReport{
+		NObserved:             m.n,
+		NTraining:             ref.NSamples,
+		Sufficient:            m.n >= th.MinSamples && m.n > 0,
+		TrainClassProportions: ref.ClassProportions,
+		ClassPSIPValue:        1,
+		Alerts:                []string{},
+	}
+	level := func(l string) string {
+		if !report.Sufficient {
+			return DriftOK
+		}
+		return l
+	}
+
+	for j, f := range ref.Features {
+		live := proportions(m.binCounts[j])
+		fd := FeatureDrift{
+			Index:     j,
+			Feature:   f.Name,
+			PSIPValue: 1,
+			KSPValue:  1,
+			TrainMean: f.Mean,
+			Level:     DriftOK,
+		}
+		if m.n > 0 {
+			fd.PSI = PSI(f.Proportions, live)
+			fd.PSIPValue = psiPValue(fd.PSI, f.Proportions, live, ref.NSamples, m.n)
+			fd.KS = binnedKS(f.Proportions, live)
+			fd.KSPValue = ksPValue(fd.KS, ref.NSamples, m.n)
+			fd.LiveMean = m.sums[j] / float64(m.n)
+			fd.OutOfRange = float64(m.outOfRange[j]) / float64(m.n)
+		}
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("x%d", j)
+		}
+		significant := fd.PSIPValue < th.PValue
+		switch {
+		case significant && fd.PSI >= th.PSIAlert:
+			fd.Level = level(DriftAlert)
+		case significant && fd.PSI >= th.PSIWarning, fd.KSPValue < th.PValue, fd.OutOfRange > th.OutOfRange:
+			fd.Level = level(DriftWarning)
+		}
+		if fd.Level != DriftOK {
+			report.Alerts = append(report.Alerts, fmt.Sprintf("%s: %s (psi %.3f p=%.4f, ks %.3f p=%.4f, out of range %.1f%%)",
+				name, fd.Level, fd.PSI, fd.PSIPValue, fd.KS, fd.KSPValue, 100*fd.OutOfRange))
+		}
+		report.Features = append(report.Features, fd)
+	}
+
+	report.ClassProportions = proportions(m.classCounts)
+	report.ClassLevel = DriftOK
+	if m.n > 0 {
+		expected := ref.ClassProportions
+		for len(expected) < len(report.ClassProportions) {
+			expected = append(append([]float64(nil), expected...), 0)
+		}
+		report.ClassPSI = PSI(expected, report.ClassProportions)
+		report.ClassPSIPValue = psiPValue(report.ClassPSI, expected, report.ClassProportions, ref.NSamples, m.n)
+		significant := report.ClassPSIPValue < th.PValue
+		switch {
+		case significant && report.ClassPSI >= th.PSIAlert:
+			report.ClassLevel = level(DriftAlert)
+		case significant && report.ClassPSI >= th.PSIWarning:
+			report.ClassLevel = level(DriftWarning)
+		}
+		if report.ClassLevel != DriftOK {
+			report.Alerts = append(report.Alerts, fmt.Sprintf("predicted classes: %s (psi %.3f p=%.4f)",
+				report.ClassLevel, report.ClassPSI, report.ClassPSIPValue))
+		}
+	}
+
+	report.Level = report.ClassLevel
+	for _, fd := range report.Features {
+		if fd.Level == DriftAlert || (fd.Level == DriftWarning && report.Level == DriftOK) {
+			report.Level = fd.Level
+		}
+	}
+	return report
+}
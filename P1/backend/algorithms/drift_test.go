@@ -0,0 +1,81 @@
+package algorithms
+
+import (
+	"math/rand"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func TestPSIIdentical(t *testing.T) {
+	for _, p := range [][]float64{
+		{0.25, 0.25, 0.25, 0.25},
+		{0.7, 0.2, 0.1},
+		{0.5, 0, 0.5}, // empty bins
+	} {
+		if psi := PSI(p, p); psi != 0 {
+			t.Errorf("PSI(%v, %v) = %v, want 0", p, p, psi)
+		}
+	}
+	if psi := PSI([]float64{0.5, 0.5}, []float64{0.9, 0.1}); psi <= 0 {
+		t.Errorf("PSI of different distributions = %v, want > 0", psi)
+	}
+}
+
+// driftSample draws n rows of two uniform [0, 1) features; shift is added
+// to the second one.
+func driftSample(rng *rand.Rand, n int, shift float64) (*mat.Dense, []int) {
+	X := mat.NewDense(n, 2, nil)
+	y := make([]int, n)
+	for i := 0; i < n; i++ {
+		X.Set(i, 0, rng.Float64())
+		X.Set(i, 1, rng.Float64()+shift)
+		y[i] = i % 2
+	}
+	return X, y
+}
+
+func TestDriftMonitor(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	Xtrain, ytrain := driftSample(rng, 2000, 0)
+	ref := NewTrainingStats(Xtrain, ytrain, []string{"a", "b"})
+	th := DefaultDriftThresholds()
+
+	// live inputs from the training distribution raise nothing
+	m := NewDriftMonitor(ref)
+	X, y := driftSample(rng, 500, 0)
+	if err := m.Observe(X, y); err != nil {
+		t.Fatal(err)
+	}
+	report := m.Report(th)
+	if report.Level != DriftOK {
+		t.Errorf("same distribution: level %s, alerts %v", report.Level, report.Alerts)
+	}
+
+	// shifting "b" by half its range is an alert on "b" only
+	m.Reset()
+	X, y = driftSample(rng, 500, 0.5)
+	if err := m.Observe(X, y); err != nil {
+		t.Fatal(err)
+	}
+	report = m.Report(th)
+	if report.Level != DriftAlert {
+		t.Errorf("shifted feature: level %s, want %s", report.Level, DriftAlert)
+	}
+	if got := report.Features[0].Level; got != DriftOK {
+		t.Errorf("feature a: level %s, want %s", got, DriftOK)
+	}
+	if got := report.Features[1]; got.Level != DriftAlert || got.PSI < th.PSIAlert {
+		t.Errorf("feature b: level %s psi %.3f, want %s with psi >= %.2f", got.Level, got.PSI, DriftAlert, th.PSIAlert)
+	}
+
+	// below MinSamples nothing is raised, however shifted
+	m.Reset()
+	X, y = driftSample(rng, th.MinSamples-1, 0.5)
+	if err := m.Observe(X, y); err != nil {
+		t.Fatal(err)
+	}
+	if report := m.Report(th); report.Sufficient || report.Level != DriftOK {
+		t.Errorf("%d observations: sufficient %v, level %s", th.MinSamples-1, report.Sufficient, report.Level)
+	}
+}
@@ -0,0 +1,262 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Ensemble combines several classifiers by soft voting: the weighted
+// average of their PredictProba outputs.
+type Ensemble struct {
+	Members []Classifier
+	Weights []float64 // one per member, normalized when predicting
+	// MinAgreement is the share of the total weight that must vote for the
+	// ensemble class; below it the prediction is flagged as low confidence.
+	MinAgreement float64
+}
+
+// MemberPrediction is the vote of one member for one sample.
+type MemberPrediction struct {
+	ModelType string    `json:"model_type"`
+	Weight    float64   `json:"weight"`
+	Class     int       `json:"class"`
+	Probs     []float64 `json:"probs"`
+}
+
+// EnsembleVote describes how the members voted for one sample.
+type EnsembleVote struct {
+	Class         int                `json:"class"`
+	Agreement     float64            `json:"agreement"`
+	LowConfidence bool               `json:"low_confidence"`
+	Members       []MemberPrediction `json:"members"`
+}
+
+// NewEnsemble builds an ensemble. weights may be nil for equal weights.
+func NewEnsemble(members []Classifier, weights []float64) (*Ensemble, error) {
+	if len(members) == 0 {
+		return nil, fmt.Errorf("NewEnsemble: no members: %w", ErrInvalidParam)
+	}
+	if weights == nil {
+		weights = make([]float64, len(members))
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != len(members) {
+		return nil, fmt.Errorf("NewEnsemble: %d weights for %d members: %w", len(weights), len(members), ErrInvalidParam)
+	}
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 {
+			return nil, fmt.Errorf("NewEnsemble: weights must be non-negative: %w", ErrInvalidParam)
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("NewEnsemble: weights sum to zero: %w", ErrInvalidParam)
+	}
+	return &Ensemble{Members: members, Weights: weights, MinAgreement: 1}, nil
+}
+
+// ModelType implements Classifier.
+func (e *Ensemble) ModelType() string {
+	return ModelEnsemble
+}
+
+// TrainingStats implements StatsCarrier with the statistics of the first
+// member that has them; members are trained on the same data.
+func (e *Ensemble) TrainingStats() *TrainingStats {
+	for _, m := range e.Members {
+		if sc, ok := m.(StatsCarrier); ok && sc.TrainingStats() != nil {
+			return sc.TrainingStats()
+		}
+	}
+	return nil
+}
+
+// Fit trains every member on the same data.
+func (e *Ensemble) Fit(X *mat.Dense, y []int) error {
+	for i, m := range e.Members {
+		if err := m.Fit(X, y); err != nil {
+			return fmt.Errorf("Ensemble.Fit: member %d (%s): %w", i, m.ModelType(), err)
+		}
+	}
+	return nil
+}
+
+// memberProbs returns the PredictProba of every member and the largest
+// number of classes among them.
+func (e *Ensemble) memberProbs(X *mat.Dense) ([]*mat.Dense, int, error) {
+	if len(e.Members) == 0 {
+		return nil, 0, fmt.Errorf("Ensemble: %w", ErrNotTrained)
+	}
+	probs := make([]*mat.Dense, len(e.Members))
+	nClasses := 0
+	for i, m := range e.Members {
+		p, err := m.PredictProba(X)
+		if err != nil {
+			return nil, 0, fmt.Errorf("Ensemble: member %d (%s): %w", i, m.ModelType(), err)
+		}
+		if _, c := p.Dims(); c > nClasses {
+			nClasses = c
+		}
+		probs[i] = p
+	}
+	return probs, nClasses, nil
+}
+
+// PredictProba returns the weighted average of the members' probabilities.
+// Members trained on fewer classes contribute 0 to the missing ones.
+func (e *Ensemble) PredictProba(X *mat.Dense) (*mat.Dense, error) {
+	probs, nClasses, err := e.memberProbs(X)
+	if err != nil {
+		return nil, err
+	}
+	nSamples, _ := X.Dims()
+	total := 0.0
+	for _, w := range e.Weights {
+		total += w
+	}
+
+	out := mat.NewDense(nSamples, nClasses, nil)
+	for m, p := range probs {
+		w := e.Weights[m] / total
+		for i := 0; i < nSamples; i++ {
+			dst := out.RawRowView(i)
+			for k, v := range p.RawRowView(i) {
+				dst[k] += w * v
+			}
+		}
+	}
+	return out, nil
+}
+
+// Predict returns argmax class index for each row.
+func (e *Ensemble) Predict(X *mat.Dense) ([]int, error) {
+	probs, err := e.PredictProba(X)
+	if err != nil {
+		return nil, err
+	}
+	return argmaxRows(probs), nil
+}
+
+// Votes returns, for every sample, the prediction of each member and how
+// much of the ensemble weight agrees with the ensemble class.
+func (e *Ensemble) Votes(X *mat.Dense) ([]EnsembleVote, error) {
+	probs, _, err := e.memberProbs(X)
+	if err != nil {
+		return nil, err
+	}
+	avg, err := e.PredictProba(X)
+	if err != nil {
+		return nil, err
+	}
+	nSamples, _ := X.Dims()
+	total := 0.0
+	for _, w := range e.Weights {
+		total += w
+	}
+
+	votes := make([]EnsembleVote, nSamples)
+	for i := 0; i < nSamples; i++ {
+		vote := EnsembleVote{Class: argmaxRow(avg.RawRowView(i))}
+		for m, p := range probs {
+			row := append([]float64(nil), p.RawRowView(i)...)
+			class := argmaxRow(row)
+			vote.Members = append(vote.Members, MemberPrediction{
+				ModelType: e.Members[m].ModelType(),
+				Weight:    e.Weights[m],
+				Class:     class,
+				Probs:     row,
+			})
+			if class == vote.Class {
+				vote.Agreement += e.Weights[m] / total
+			}
+		}
+		vote.LowConfidence = vote.Agreement < e.MinAgreement
+		votes[i] = vote
+	}
+	return votes, nil
+}
+
+// ===== Model persistence to disk =====
+
+// ensembleFile embeds the full artifact of every member, so the ensemble is
+// a single self-contained file.
+type ensembleFile struct {
+	ModelType    string            `json:"model_type"`
+	Weights      []float64         `json:"weights"`
+	MinAgreement float64           `json:"min_agreement"`
+	Members      []json.RawMessage `json:"members"`
+}
+
+// SaveToFile saves the ensemble and all its members to a JSON file.
+func (e *Ensemble) SaveToFile(path string) error {
+	fileStruct, err := e.artifact()
+	if err != nil {
+		return err
+	}
+	return writeModelJSON(path, fileStruct)
+}
+
+// artifact builds the file struct written by SaveToFile.
+func (e *Ensemble) artifact() (any, error) {
+	fileStruct := ensembleFile{
+		ModelType:    ModelEnsemble,
+		Weights:      e.Weights,
+		MinAgreement: e.MinAgreement,
+	}
+	for i, m := range e.Members {
+		enc, ok := m.(artifactEncoder)
+		if !ok {
+			return nil, fmt.Errorf("SaveToFile: member %d (%s) cannot be serialized", i, m.ModelType())
+		}
+		member, err := enc.artifact()
+		if err != nil {
+			return nil, fmt.Errorf("SaveToFile: member %d: %w", i, err)
+		}
+		raw, err := json.Marshal(member)
+		if err != nil {
+			return nil, err
+		}
+		fileStruct.Members = append(fileStruct.Members, raw)
+	}
+	return fileStruct, nil
+}
+
+// LoadEnsemble loads an ensemble from a JSON file.
+func LoadEnsemble(path string) (*Ensemble, error) {
+	c, err := LoadClassifier(path)
+	if err != nil {
+		return nil, err
+	}
+	e, ok := c.(*Ensemble)
+	if !ok {
+		return nil, fmt.Errorf("LoadEnsemble: %s is a %s model", path, c.ModelType())
+	}
+	return e, nil
+}
+
+// decodeEnsemble builds an ensemble from the JSON of its artifact.
+func decodeEnsemble(bytes []byte) (*Ensemble, error) {
+	var fileStruct ensembleFile
+	if err := json.Unmarshal(bytes, &fileStruct); err != nil {
+		return nil, err
+	}
+	members := make([]Classifier, len(fileStruct.Members))
+	for i, raw := range fileStruct.Members {
+		m, err := decodeClassifier(raw)
+		if err != nil {
+			return nil, fmt.Errorf("LoadEnsemble: member %d: %w", i, err)
+		}
+		members[i] = m
+	}
+	e, err := NewEnsemble(members, fileStruct.Weights)
+	if err != nil {
+		return nil, fmt.Errorf("LoadEnsemble: %w", err)
+	}
+	e.MinAgreement = fileStruct.MinAgreement
+	return e, nil
+}
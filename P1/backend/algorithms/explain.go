@@ -0,0 +1,84 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Contribution is the effect of one feature on the logit difference between
+// the predicted class and the runner-up: value * (W[j,pred] - W[j,runnerUp]).
+type Contribution struct {
+	Feature      string  `json:"feature"`
+	Value        float64 `json:"value"`
+	Contribution float64 `json:"contribution"`
+}
+
+// Explanation describes why a single sample got its predicted class.
+// Since the model is linear, the logit margin is exactly
+// Bias + sum(Contributions).
+type Explanation struct {
+	PredictedClass int            `json:"predicted_class"`
+	RunnerUpClass  int            `json:"runner_up_class"`
+	Margin         float64        `json:"margin"`
+	Bias           float64        `json:"bias"`
+	Contributions  []Contribution `json:"contributions"`
+}
+
+// Explain returns the per-feature contributions for the sample x, sorted by
+// absolute contribution (largest first). names labels the features; when
+// it is nil the model FeatureNames are used.
+func (m *SoftmaxRegression) Explain(x []float64, names []string) (*Explanation, error) {
+	if m.W == nil || m.B == nil {
+		return nil, fmt.Errorf("Explain: %w", ErrNotTrained)
+	}
+	X := mat.NewDense(1, len(x), x)
+	if err := m.validateX("Explain", X); err != nil {
+		return nil, err
+	}
+	_, nClasses := m.W.Dims()
+	if nClasses < 2 {
+		return nil, fmt.Errorf("Explain: model has a single class: %w", ErrDimensionMismatch)
+	}
+	if names == nil {
+		names = m.FeatureNames
+	}
+
+	_, probs := m.forward(X)
+	row := probs.RawRowView(0)
+	pred := decideClass(row, m.Thresholds)
+
+	runnerUp := -1
+	for k := 0; k < nClasses; k++ {
+		if k != pred && (runnerUp == -1 || row[k] > row[runnerUp]) {
+			runnerUp = k
+		}
+	}
+
+	exp := &Explanation{
+		PredictedClass: pred,
+		RunnerUpClass:  runnerUp,
+		Bias:           m.B.AtVec(pred) - m.B.AtVec(runnerUp),
+		Contributions:  make([]Contribution, len(x)),
+	}
+	exp.Margin = exp.Bias
+	for j, v := range x {
+		name := fmt.Sprintf("x%d", j)
+		if j < len(names) {
+			name = names[j]
+		}
+		c := v * (m.W.At(j, pred) - m.W.At(j, runnerUp))
+		if c == 0 {
+			c = 0 // avoid reporting -0 for absent features
+		}
+		exp.Contributions[j] = Contribution{Feature: name, Value: v, Contribution: c}
+		exp.Margin += c
+	}
+
+	sort.SliceStable(exp.Contributions, func(a, b int) bool {
+		return math.Abs(exp.Contributions[a].Contribution) > math.Abs(exp.Contributions[b].Contribution)
+	})
+	return exp, nil
+}
@@ -0,0 +1,353 @@
+package algorithms
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrCorruptArtifact is returned for an artifact whose checksum or layout
+// is wrong.
+var ErrCorruptArtifact = errors.New("corrupt model artifact")
+
+// Export formats of a model, besides the JSON artifact of SaveToFile.
+const (
+	FormatJSON   = "json"
+	FormatBinary = "binary"
+	FormatPMML   = "pmml"
+)
+
+// ExportFormats lists the formats accepted by ExportModel.
+var ExportFormats = []string{FormatJSON, FormatBinary, FormatPMML}
+
+// ExportModel writes c to path in the given format. binary and pmml only
+// support softmax models.
+func ExportModel(c Classifier, path, format string) error {
+	if format == FormatJSON {
+		return c.SaveToFile(path)
+	}
+	m, ok := c.(*SoftmaxRegression)
+	if !ok {
+		return fmt.Errorf("ExportModel: %s export only supports softmax, got %s: %w", format, c.ModelType(), ErrInvalidParam)
+	}
+	switch format {
+	case FormatBinary:
+		data, err := m.MarshalBinary()
+		if err != nil {
+			return err
+		}
+		return saveArtifact(path, data)
+	case FormatPMML:
+		// PMML is not loaded back, so it has no checksum sidecar
+		data, err := m.MarshalPMML()
+		if err != nil {
+			return err
+		}
+		return writeFileAtomic(path, data)
+	default:
+		return fmt.Errorf("ExportModel: unknown format %q: %w", format, ErrInvalidParam)
+	}
+}
+
+// ===== Binary format =====
+
+// binaryMagic starts every binary artifact; LoadClassifier uses it to tell
+// binary from JSON artifacts.
+var binaryMagic = [4]byte{'U', 'M', 'S', 'X'}
+
+const binaryVersion uint16 = 1
+
+// MarshalBinary encodes the model in the compact binary format, little
+// endian:
+//
+//	magic "UMSX" | version uint16 | n_features uint32 | n_classes uint32
+//	lr float64 | n_iter int64 | reg_lambda float64 | l1_ratio float64 | seed int64
+//	penalty string | W float64[n_features*n_classes] (row-major) | B float64[n_classes]
+//	thresholds uint32 count + float64s | feature_names uint32 count + strings
+//	crc32 (IEEE) of everything before it
+//
+// Strings are a uint16 length followed by the UTF-8 bytes. The training
+// statistics are not stored: a binary model has no drift reference.
+func (m *SoftmaxRegression) MarshalBinary() ([]byte, error) {
+	a, err := m.artifact()
+	if err != nil {
+		return nil, err
+	}
+	f := a.(softmaxModelFile)
+
+	var buf bytes.Buffer
+	w := func(v any) { binary.Write(&buf, binary.LittleEndian, v) } // bytes.Buffer never fails
+	writeString := func(s string) error {
+		if len(s) > math.MaxUint16 {
+			return fmt.Errorf("MarshalBinary: string of %d bytes too long: %w", len(s), ErrInvalidParam)
+		}
+		w(uint16(len(s)))
+		buf.WriteString(s)
+		return nil
+	}
+
+	w(binaryMagic)
+	w(binaryVersion)
+	w(uint32(f.NFeatures))
+	w(uint32(f.NClasses))
+	w(f.Lr)
+	w(int64(f.NIter))
+	w(f.RegLambda)
+	w(f.L1Ratio)
+	w(f.Seed)
+	if err := writeString(f.Penalty); err != nil {
+		return nil, err
+	}
+	w(f.W)
+	w(f.B)
+	w(uint32(len(f.Thresholds)))
+	w(f.Thresholds)
+	w(uint32(len(f.FeatureNames)))
+	for _, name := range f.FeatureNames {
+		if err := writeString(name); err != nil {
+			return nil, err
+		}
+	}
+	w(crc32.ChecksumIEEE(buf.Bytes()))
+	return buf.Bytes(), nil
+}
+
+// isBinaryArtifact reports whether data starts with the binary magic.
+func isBinaryArtifact(data []byte) bool {
+	return len(data) >= len(binaryMagic) && bytes.Equal(data[:len(binaryMagic)], binaryMagic[:])
+}
+
+// decodeSoftmaxBinary is the inverse of MarshalBinary. The checksum is
+// verified before anything else is read.
+func decodeSoftmaxBinary(data []byte) (*SoftmaxRegression, error) {
+	if !isBinaryArtifact(data) || len(data) < len(binaryMagic)+2+4 {
+		return nil, fmt.Errorf("decodeSoftmaxBinary: not a binary artifact: %w", ErrCorruptArtifact)
+	}
+	body, sum := data[:len(data)-4], binary.LittleEndian.Uint32(data[len(data)-4:])
+	if crc32.ChecksumIEEE(body) != sum {
+		return nil, fmt.Errorf("decodeSoftmaxBinary: checksum mismatch: %w", ErrCorruptArtifact)
+	}
+
+	r := bytes.NewReader(body[len(binaryMagic):])
+	var readErr error
+	read := func(v any) {
+		if readErr == nil {
+			readErr = binary.Read(r, binary.LittleEndian, v)
+		}
+	}
+	// counts are checked against the remaining bytes before allocating
+	count := func(n int64, size int) int {
+		if readErr == nil && n*int64(size) > int64(r.Len()) {
+			readErr = io.ErrUnexpectedEOF
+		}
+		if readErr != nil {
+			return 0
+		}
+		return int(n)
+	}
+	readString := func() string {
+		var n uint16
+		read(&n)
+		b := make([]byte, count(int64(n), 1))
+		read(b)
+		return string(b)
+	}
+
+	var version uint16
+	read(&version)
+	if readErr == nil && version != binaryVersion {
+		return nil, fmt.Errorf("decodeSoftmaxBinary: unsupported version %d: %w", version, ErrCorruptArtifact)
+	}
+	var nFeatures, nClasses uint32
+	var nIter int64
+	f := softmaxModelFile{ModelType: ModelSoftmax}
+	read(&nFeatures)
+	read(&nClasses)
+	read(&f.Lr)
+	read(&nIter)
+	read(&f.RegLambda)
+	read(&f.L1Ratio)
+	read(&f.Seed)
+	f.Penalty = readString()
+	if readErr == nil && (nFeatures == 0 || nClasses == 0) {
+		return nil, fmt.Errorf("decodeSoftmaxBinary: empty weights: %w", ErrCorruptArtifact)
+	}
+	f.NFeatures, f.NClasses, f.NIter = int(nFeatures), int(nClasses), int(nIter)
+	f.W = make([]float64, count(int64(nFeatures)*int64(nClasses), 8))
+	read(f.W)
+	f.B = make([]float64, count(int64(nClasses), 8))
+	read(f.B)
+
+	var n uint32
+	read(&n)
+	if n > 0 {
+		f.Thresholds = make([]float64, count(int64(n), 8))
+		read(f.Thresholds)
+	}
+	read(&n)
+	nNames := count(int64(n), 2)
+	for i := 0; i < nNames; i++ {
+		f.FeatureNames = append(f.FeatureNames, readString())
+	}
+	if readErr == nil && r.Len() != 0 {
+		readErr = fmt.Errorf("%d trailing bytes", r.Len())
+	}
+	if readErr != nil {
+		return nil, fmt.Errorf("decodeSoftmaxBinary: %v: %w", readErr, ErrCorruptArtifact)
+	}
+	return softmaxFromFile(f)
+}
+
+// ===== PMML =====
+
+// PMML 4.4 documents for a softmax regression: one RegressionTable per class
+// with normalizationMethod="softmax", which is exactly PredictProba.
+// Coefficients are written with the shortest representation that parses
+// back to the same float64.
+
+type pmmlDocument struct {
+	XMLName        xml.Name            `xml:"PMML"`
+	Xmlns          string              `xml:"xmlns,attr"`
+	Version        string              `xml:"version,attr"`
+	Header         pmmlHeader          `xml:"Header"`
+	DataDictionary pmmlDataDictionary  `xml:"DataDictionary"`
+	Model          pmmlRegressionModel `xml:"RegressionModel"`
+}
+
+type pmmlHeader struct {
+	Description string          `xml:"description,attr"`
+	Application pmmlApplication `xml:"Application"`
+}
+
+type pmmlApplication struct {
+	Name string `xml:"name,attr"`
+}
+
+type pmmlDataDictionary struct {
+	NumberOfFields int             `xml:"numberOfFields,attr"`
+	Fields         []pmmlDataField `xml:"DataField"`
+}
+
+type pmmlDataField struct {
+	Name     string      `xml:"name,attr"`
+	Optype   string      `xml:"optype,attr"`
+	DataType string      `xml:"dataType,attr"`
+	Values   []pmmlValue `xml:"Value,omitempty"`
+}
+
+type pmmlValue struct {
+	Value string `xml:"value,attr"`
+}
+
+type pmmlExtension struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type pmmlRegressionModel struct {
+	FunctionName        string             `xml:"functionName,attr"`
+	NormalizationMethod string             `xml:"normalizationMethod,attr"`
+	ModelName           string             `xml:"modelName,attr"`
+	Extensions          []pmmlExtension    `xml:"Extension,omitempty"`
+	MiningSchema        []pmmlMiningField  `xml:"MiningSchema>MiningField"`
+	Tables              []pmmlRegressionTb `xml:"RegressionTable"`
+}
+
+type pmmlMiningField struct {
+	Name      string `xml:"name,attr"`
+	UsageType string `xml:"usageType,attr,omitempty"`
+}
+
+type pmmlRegressionTb struct {
+	Intercept      string          `xml:"intercept,attr"`
+	TargetCategory string          `xml:"targetCategory,attr"`
+	Predictors     []pmmlPredictor `xml:"NumericPredictor"`
+}
+
+type pmmlPredictor struct {
+	Name        string `xml:"name,attr"`
+	Coefficient string `xml:"coefficient,attr"`
+}
+
+// pmmlTarget is the name of the predicted field.
+const pmmlTarget = "class"
+
+func formatExact(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// MarshalPMML encodes the weights as a PMML 4.4 RegressionModel. Features
+// without FeatureNames are called x0, x1, ...; the target categories are the
+// class indices. Decision thresholds have no PMML equivalent and are stored
+// in an Extension named "unmatch_thresholds".
+func (m *SoftmaxRegression) MarshalPMML() ([]byte, error) {
+	a, err := m.artifact()
+	if err != nil {
+		return nil, err
+	}
+	f := a.(softmaxModelFile)
+
+	names := f.FeatureNames
+	if len(names) == 0 {
+		names = make([]string, f.NFeatures)
+		for j := range names {
+			names[j] = "x" + strconv.Itoa(j)
+		}
+	}
+
+	doc := pmmlDocument{
+		Xmlns:   "http://www.dmg.org/PMML-4_4",
+		Version: "4.4",
+		Header: pmmlHeader{
+			Description: "softmax regression exported by unmatch",
+			Application: pmmlApplication{Name: "unmatch"},
+		},
+		Model: pmmlRegressionModel{
+			FunctionName:        "classification",
+			NormalizationMethod: "softmax",
+			ModelName:           ModelSoftmax,
+		},
+	}
+	target := pmmlDataField{Name: pmmlTarget, Optype: "categorical", DataType: "integer"}
+	for k := 0; k < f.NClasses; k++ {
+		target.Values = append(target.Values, pmmlValue{strconv.Itoa(k)})
+	}
+	for _, name := range names {
+		doc.DataDictionary.Fields = append(doc.DataDictionary.Fields,
+			pmmlDataField{Name: name, Optype: "continuous", DataType: "double"})
+		doc.Model.MiningSchema = append(doc.Model.MiningSchema, pmmlMiningField{Name: name})
+	}
+	doc.DataDictionary.Fields = append(doc.DataDictionary.Fields, target)
+	doc.DataDictionary.NumberOfFields = len(doc.DataDictionary.Fields)
+	doc.Model.MiningSchema = append(doc.Model.MiningSchema, pmmlMiningField{Name: pmmlTarget, UsageType: "target"})
+
+	if len(f.Thresholds) > 0 {
+		values := make([]string, len(f.Thresholds))
+		for k, t := range f.Thresholds {
+			values[k] = formatExact(t)
+		}
+		doc.Model.Extensions = []pmmlExtension{{Name: "unmatch_thresholds", Value: strings.Join(values, " ")}}
+	}
+
+	for k := 0; k < f.NClasses; k++ {
+		table := pmmlRegressionTb{Intercept: formatExact(f.B[k]), TargetCategory: strconv.Itoa(k)}
+		for j, name := range names {
+			table.Predictors = append(table.Predictors,
+				pmmlPredictor{Name: name, Coefficient: formatExact(f.W[j*f.NClasses+k])})
+		}
+		doc.Model.Tables = append(doc.Model.Tables, table)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
@@ -0,0 +1,238 @@
+package algorithms
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var (
+	ErrUnsigned     = errors.New("model artifact is not signed")
+	ErrBadSignature = errors.New("model artifact signature is invalid")
+)
+
+// Environment variables with the key files used by LoadKeysFromEnv.
+const (
+	SigningKeyEnv = "UNMATCH_SIGNING_KEY"
+	VerifyKeyEnv  = "UNMATCH_VERIFY_KEY"
+)
+
+// ArtifactSignature is the sidecar file "<artifact>.sig" written next to
+// every loadable artifact. SHA256 is always set; Ed25519 is the base64
+// signature of the artifact bytes, set by SignArtifact.
+type ArtifactSignature struct {
+	SHA256  string `json:"sha256"`
+	Ed25519 string `json:"ed25519,omitempty"`
+}
+
+// SignaturePath returns the sidecar path of an artifact.
+func SignaturePath(path string) string {
+	return path + ".sig"
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// saveArtifact writes an artifact and its checksum sidecar. A previous
+// signature does not apply to the new bytes, so it is dropped.
+func saveArtifact(path string, data []byte) error {
+	if err := writeFileAtomic(path, data); err != nil {
+		return err
+	}
+	return writeSignature(path, ArtifactSignature{SHA256: checksum(data)})
+}
+
+func writeSignature(path string, sig ArtifactSignature) error {
+	bytes, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(SignaturePath(path), bytes)
+}
+
+// readSignature returns the sidecar of path, or nil if there is none.
+func readSignature(path string) (*ArtifactSignature, error) {
+	bytes, err := os.ReadFile(SignaturePath(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var sig ArtifactSignature
+	if err := json.Unmarshal(bytes, &sig); err != nil {
+		return nil, fmt.Errorf("%s: %v: %w", SignaturePath(path), err, ErrCorruptArtifact)
+	}
+	return &sig, nil
+}
+
+// SignArtifact signs the artifact at path with key and rewrites its sidecar.
+func SignArtifact(path string, key ed25519.PrivateKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return signArtifact(path, data, key)
+}
+
+func signArtifact(path string, data []byte, key ed25519.PrivateKey) error {
+	return writeSignature(path, ArtifactSignature{
+		SHA256:  checksum(data),
+		Ed25519: base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	})
+}
+
+// verifyChecksum is verifyArtifact without a key for artifacts that must
+// have a sidecar: a missing one is ErrUnsigned.
+func verifyChecksum(path string, data []byte) error {
+	sig, err := readSignature(path)
+	if err != nil {
+		return err
+	}
+	if sig == nil {
+		return fmt.Errorf("%s: no %s: %w", path, SignaturePath(path), ErrUnsigned)
+	}
+	return verifyArtifact(path, data, nil)
+}
+
+// verifyArtifact checks data, the content of path, against its sidecar:
+//
+//   - a sidecar with another SHA-256 is ErrCorruptArtifact;
+//   - with key == nil, an artifact without sidecar is accepted (artifacts
+//     written before checksums existed);
+//   - with a key, the artifact must have a valid Ed25519 signature
+//     (ErrUnsigned, ErrBadSignature).
+func verifyArtifact(path string, data []byte, key ed25519.PublicKey) error {
+	sig, err := readSignature(path)
+	if err != nil {
+		return err
+	}
+	if sig == nil {
+		if key != nil {
+			return fmt.Errorf("%s: %w", path, ErrUnsigned)
+		}
+		return nil
+	}
+	if !strings.EqualFold(sig.SHA256, checksum(data)) {
+		return fmt.Errorf("%s: sha256 does not match %s: %w", path, SignaturePath(path), ErrCorruptArtifact)
+	}
+	if key == nil {
+		return nil
+	}
+	if sig.Ed25519 == "" {
+		return fmt.Errorf("%s: %w", path, ErrUnsigned)
+	}
+	signature, err := base64.StdEncoding.DecodeString(sig.Ed25519)
+	if err != nil || !ed25519.Verify(key, data, signature) {
+		return fmt.Errorf("%s: %w", path, ErrBadSignature)
+	}
+	return nil
+}
+
+// VerifyArtifact reads the artifact at path and checks it against its
+// sidecar (see LoadVerifiedClassifier).
+func VerifyArtifact(path string, key ed25519.PublicKey) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return verifyArtifact(path, data, key)
+}
+
+// LoadVerifiedClassifier is LoadClassifier that refuses tampered artifacts:
+// the SHA-256 of the sidecar must match, and with a non-nil key the
+// artifact must carry a valid Ed25519 signature. The bytes are read once,
+// so the verified bytes are the decoded ones.
+func LoadVerifiedClassifier(path string, key ed25519.PublicKey) (Classifier, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyArtifact(path, data, key); err != nil {
+		return nil, err
+	}
+	return decodeClassifier(data)
+}
+
+// ===== Keys =====
+
+// Key files hold the base64 of the 32-byte Ed25519 seed (private) or public
+// key.
+
+// GenerateSigningKey creates a key pair and writes the private key to
+// privPath (mode 0600) and the public key to pubPath.
+func GenerateSigningKey(privPath, pubPath string) error {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return err
+	}
+	for _, p := range []string{privPath, pubPath} {
+		if _, err := os.Stat(p); err == nil {
+			return fmt.Errorf("GenerateSigningKey: %s already exists: %w", p, os.ErrExist)
+		}
+	}
+	if err := os.WriteFile(privPath, []byte(base64.StdEncoding.EncodeToString(priv.Seed())+"\n"), 0o600); err != nil {
+		return err
+	}
+	return os.WriteFile(pubPath, []byte(base64.StdEncoding.EncodeToString(pub)+"\n"), 0o644)
+}
+
+func readKeyFile(path string, size int) ([]byte, error) {
+	text, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(text)))
+	if err != nil || len(key) != size {
+		return nil, fmt.Errorf("%s: expected the base64 of a %d-byte ed25519 key: %w", path, size, ErrInvalidParam)
+	}
+	return key, nil
+}
+
+// LoadSigningKey reads a private key written by GenerateSigningKey.
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	seed, err := readKeyFile(path, ed25519.SeedSize)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// LoadVerifyKey reads a public key written by GenerateSigningKey.
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	key, err := readKeyFile(path, ed25519.PublicKeySize)
+	if err != nil {
+		return nil, err
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// LoadKeysFromEnv sets SigningKey and VerifyKey of r from the key files
+// named by SigningKeyEnv and VerifyKeyEnv; unset variables leave the key
+// nil.
+func (r *Registry) LoadKeysFromEnv() error {
+	if path := os.Getenv(SigningKeyEnv); path != "" {
+		key, err := LoadSigningKey(path)
+		if err != nil {
+			return err
+		}
+		r.SigningKey = key
+	}
+	if path := os.Getenv(VerifyKeyEnv); path != "" {
+		key, err := LoadVerifyKey(path)
+		if err != nil {
+			return err
+		}
+		r.VerifyKey = key
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package algorithms
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+func testKeys(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pub, priv
+}
+
+// testModel trains a small two-class softmax model.
+func testModel(t *testing.T) (*SoftmaxRegression, *mat.Dense, []int) {
+	t.Helper()
+	X := mat.NewDense(6, 2, []float64{0, 0, 0, 1, 0, 2, 1, 0, 1, 1, 1, 2})
+	y := []int{0, 0, 0, 1, 1, 1}
+	m := NewSoftmaxRegression(0.1, 50, 1e-3)
+	if err := m.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	return m, X, y
+}
+
+func TestVerifyArtifactTampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	m, _, _ := testModel(t)
+	if err := m.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifact(path, nil); err != nil {
+		t.Fatalf("untouched artifact: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/2] ^= 1
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifact(path, nil); !errors.Is(err, ErrCorruptArtifact) {
+		t.Errorf("tampered artifact: got %v, want ErrCorruptArtifact", err)
+	}
+	if _, err := LoadVerifiedClassifier(path, nil); !errors.Is(err, ErrCorruptArtifact) {
+		t.Errorf("loading a tampered artifact: got %v, want ErrCorruptArtifact", err)
+	}
+}
+
+func TestVerifyArtifactMissingSidecar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	m, _, _ := testModel(t)
+	if err := m.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(SignaturePath(path)); err != nil {
+		t.Fatal(err)
+	}
+
+	// without a key, artifacts older than the sidecars still load
+	if err := VerifyArtifact(path, nil); err != nil {
+		t.Errorf("without key: %v", err)
+	}
+	pub, _ := testKeys(t)
+	if err := VerifyArtifact(path, pub); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("with key: got %v, want ErrUnsigned", err)
+	}
+}
+
+func TestVerifyArtifactSignature(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	m, _, _ := testModel(t)
+	if err := m.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	pub, priv := testKeys(t)
+	if err := VerifyArtifact(path, pub); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("checksum only: got %v, want ErrUnsigned", err)
+	}
+	if err := SignArtifact(path, priv); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifact(path, pub); err != nil {
+		t.Errorf("signed artifact: %v", err)
+	}
+	other, _ := testKeys(t)
+	if err := VerifyArtifact(path, other); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("other key: got %v, want ErrBadSignature", err)
+	}
+}
+
+func TestRegistrySignRequiresSidecar(t *testing.T) {
+	r := NewRegistry(t.TempDir())
+	pub, priv := testKeys(t)
+	r.SigningKey = priv
+
+	m, X, y := testModel(t)
+	v, err := r.Register(m, NewTrainingManifest(m, X, y, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(SignaturePath(r.modelPath(v.ID))); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Sign(v.ID); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("Sign without sidecar: got %v, want ErrUnsigned", err)
+	}
+	if _, err := os.Stat(SignaturePath(r.modelPath(v.ID))); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Sign without sidecar wrote one: %v", err)
+	}
+
+	v, err = r.Register(m, NewTrainingManifest(m, X, y, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(r.modelPath(v.ID), []byte(`{"model_type": "softmax"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Sign(v.ID); !errors.Is(err, ErrCorruptArtifact) {
+		t.Errorf("Sign of a modified artifact: got %v, want ErrCorruptArtifact", err)
+	}
+
+	v, err = r.Register(m, NewTrainingManifest(m, X, y, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Sign(v.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyArtifact(r.modelPath(v.ID), pub); err != nil {
+		t.Errorf("signed version: %v", err)
+	}
+}
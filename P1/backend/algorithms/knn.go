@@ -0,0 +1,232 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// KNN is a k-nearest-neighbors classifier with Euclidean distance on
+// standardized features (so counts and probabilities weigh the same).
+type KNN struct {
+	K int // Number of neighbors
+
+	NClasses int
+	X        *mat.Dense     // standardized training samples (n x d)
+	Y        []int          // training labels (n,)
+	Mean     []float64      // per-feature mean used to standardize
+	Std      []float64      // per-feature std used to standardize (1 if constant)
+	Stats    *TrainingStats // Training-set statistics, set by Fit (see DriftMonitor)
+}
+
+// NewKNN creates an untrained model that votes with k neighbors.
+func NewKNN(k int) *KNN {
+	return &KNN{K: k}
+}
+
+// ModelType implements Classifier.
+func (m *KNN) ModelType() string {
+	return ModelKNN
+}
+
+// TrainingStats implements StatsCarrier.
+func (m *KNN) TrainingStats() *TrainingStats {
+	return m.Stats
+}
+
+// Fit stores the standardized training samples.
+func (m *KNN) Fit(X *mat.Dense, y []int) error {
+	if m.K <= 0 {
+		return fmt.Errorf("KNN.Fit: k must be positive, got %d: %w", m.K, ErrInvalidParam)
+	}
+	if err := validateMatrix("KNN.Fit", X, 0); err != nil {
+		return err
+	}
+	nSamples, nFeatures := X.Dims()
+	if err := validateLabels("KNN.Fit", y, nSamples, 0); err != nil {
+		return err
+	}
+
+	m.Stats = NewTrainingStats(X, y, nil)
+	m.Mean = make([]float64, nFeatures)
+	m.Std = make([]float64, nFeatures)
+	for i := 0; i < nSamples; i++ {
+		for j, v := range X.RawRowView(i) {
+			m.Mean[j] += v
+		}
+	}
+	for j := range m.Mean {
+		m.Mean[j] /= float64(nSamples)
+	}
+	for i := 0; i < nSamples; i++ {
+		for j, v := range X.RawRowView(i) {
+			d := v - m.Mean[j]
+			m.Std[j] += d * d
+		}
+	}
+	for j := range m.Std {
+		m.Std[j] = math.Sqrt(m.Std[j] / float64(nSamples))
+		if m.Std[j] == 0 {
+			m.Std[j] = 1
+		}
+	}
+
+	m.X = m.standardize(X)
+	m.Y = append([]int(nil), y...)
+	m.NClasses = countClasses(y)
+	return nil
+}
+
+// standardize returns (X - Mean) / Std.
+func (m *KNN) standardize(X *mat.Dense) *mat.Dense {
+	r, c := X.Dims()
+	out := mat.NewDense(r, c, nil)
+	for i := 0; i < r; i++ {
+		src := X.RawRowView(i)
+		dst := out.RawRowView(i)
+		for j := range src {
+			dst[j] = (src[j] - m.Mean[j]) / m.Std[j]
+		}
+	}
+	return out
+}
+
+// PredictProba returns an (n x K) matrix with the fraction of the k nearest
+// neighbors that belong to every class.
+func (m *KNN) PredictProba(X *mat.Dense) (*mat.Dense, error) {
+	if m.X == nil {
+		return nil, fmt.Errorf("KNN.PredictProba: %w", ErrNotTrained)
+	}
+	nTrain, nFeatures := m.X.Dims()
+	if err := validateMatrix("KNN.PredictProba", X, nFeatures); err != nil {
+		return nil, err
+	}
+	k := m.K
+	if k > nTrain {
+		k = nTrain
+	}
+
+	Xs := m.standardize(X)
+	nSamples, _ := Xs.Dims()
+	out := mat.NewDense(nSamples, m.NClasses, nil)
+
+	type neighbor struct {
+		dist  float64
+		label int
+	}
+	neighbors := make([]neighbor, nTrain)
+	for i := 0; i < nSamples; i++ {
+		x := Xs.RawRowView(i)
+		for t := 0; t < nTrain; t++ {
+			d := 0.0
+			for j, v := range m.X.RawRowView(t) {
+				d += (x[j] - v) * (x[j] - v)
+			}
+			neighbors[t] = neighbor{dist: d, label: m.Y[t]}
+		}
+		sort.SliceStable(neighbors, func(a, b int) bool { return neighbors[a].dist < neighbors[b].dist })
+
+		row := out.RawRowView(i)
+		for _, nb := range neighbors[:k] {
+			row[nb.label] += 1 / float64(k)
+		}
+	}
+	return out, nil
+}
+
+// Predict returns the majority class of the k nearest neighbors.
+func (m *KNN) Predict(X *mat.Dense) ([]int, error) {
+	probs, err := m.PredictProba(X)
+	if err != nil {
+		return nil, err
+	}
+	return argmaxRows(probs), nil
+}
+
+// ===== Model persistence to disk =====
+
+type knnFile struct {
+	ModelType     string         `json:"model_type"`
+	NFeatures     int            `json:"n_features"`
+	NClasses      int            `json:"n_classes"`
+	K             int            `json:"k"`
+	X             []float64      `json:"x"`
+	Y             []int          `json:"y"`
+	Mean          []float64      `json:"mean"`
+	Std           []float64      `json:"std"`
+	TrainingStats *TrainingStats `json:"training_stats,omitempty"`
+}
+
+// SaveToFile saves the training samples and scaling to a JSON file.
+func (m *KNN) SaveToFile(path string) error {
+	fileStruct, err := m.artifact()
+	if err != nil {
+		return err
+	}
+	return writeModelJSON(path, fileStruct)
+}
+
+// artifact builds the file struct written by SaveToFile.
+func (m *KNN) artifact() (any, error) {
+	if m.X == nil {
+		return nil, fmt.Errorf("SaveToFile: %w", ErrNotTrained)
+	}
+	_, nFeatures := m.X.Dims()
+	return knnFile{
+		ModelType:     ModelKNN,
+		NFeatures:     nFeatures,
+		NClasses:      m.NClasses,
+		K:             m.K,
+		X:             denseData(m.X),
+		Y:             m.Y,
+		Mean:          m.Mean,
+		Std:           m.Std,
+		TrainingStats: m.Stats,
+	}, nil
+}
+
+// LoadKNN loads a model from a JSON file.
+func LoadKNN(path string) (*KNN, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeKNN(bytes)
+}
+
+// decodeKNN builds a model from the JSON of its artifact.
+func decodeKNN(bytes []byte) (*KNN, error) {
+	var fileStruct knnFile
+	if err := json.Unmarshal(bytes, &fileStruct); err != nil {
+		return nil, err
+	}
+	nTrain := len(fileStruct.Y)
+	if nTrain == 0 || fileStruct.NFeatures == 0 || len(fileStruct.X) != nTrain*fileStruct.NFeatures {
+		return nil, fmt.Errorf("LoadKNN: X dimensions mismatch")
+	}
+	if len(fileStruct.Mean) != fileStruct.NFeatures || len(fileStruct.Std) != fileStruct.NFeatures {
+		return nil, fmt.Errorf("LoadKNN: mean/std dimensions mismatch")
+	}
+	if fileStruct.K <= 0 {
+		return nil, fmt.Errorf("LoadKNN: k must be positive")
+	}
+	if err := validateLabels("LoadKNN", fileStruct.Y, nTrain, fileStruct.NClasses); err != nil {
+		return nil, err
+	}
+	if err := fileStruct.TrainingStats.check("LoadKNN", fileStruct.NFeatures); err != nil {
+		return nil, err
+	}
+	return &KNN{
+		K:        fileStruct.K,
+		NClasses: fileStruct.NClasses,
+		X:        mat.NewDense(nTrain, fileStruct.NFeatures, fileStruct.X),
+		Y:        fileStruct.Y,
+		Mean:     fileStruct.Mean,
+		Std:      fileStruct.Std,
+		Stats:    fileStruct.TrainingStats,
+	}, nil
+}
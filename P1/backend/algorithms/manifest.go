@@ -0,0 +1,109 @@
+package algorithms
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const DefaultManifestPath = "./weights/softmax_manifest.json"
+
+// TrainingManifest records everything needed to reproduce a training run.
+type TrainingManifest struct {
+	CreatedAt   time.Time `json:"created_at"`
+	ModelType   string    `json:"model_type"`
+	BaseVersion string    `json:"base_version,omitempty"` // version updated with PartialFit
+	DatasetPath string    `json:"dataset_path,omitempty"`
+	DatasetHash string    `json:"dataset_sha256"`
+	NSamples    int       `json:"n_samples"`
+	NFeatures   int       `json:"n_features"`
+	NClasses    int       `json:"n_classes"`
+	Seed        int64     `json:"seed"`
+	Lr          float64   `json:"lr,omitempty"`
+	NIter       int       `json:"n_iter,omitempty"`
+	RegLambda   float64   `json:"reg_lambda,omitempty"`
+	Penalty     string    `json:"penalty,omitempty"`
+	L1Ratio     float64   `json:"l1_ratio,omitempty"`
+	Hidden      []int     `json:"hidden,omitempty"`
+	Accuracy    float64   `json:"accuracy"`
+	FinalLoss   float64   `json:"final_loss,omitempty"`
+}
+
+// HashDataset returns the SHA-256 (hex) of X and y. The hash depends only on
+// the values, so the same data gives the same hash whatever its CSV format.
+func HashDataset(X *mat.Dense, y []int) string {
+	h := sha256.New()
+	r, c := X.Dims()
+	var buf [8]byte
+
+	binary.LittleEndian.PutUint64(buf[:], uint64(r))
+	h.Write(buf[:])
+	binary.LittleEndian.PutUint64(buf[:], uint64(c))
+	h.Write(buf[:])
+	for i := 0; i < r; i++ {
+		for _, v := range X.RawRowView(i) {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			h.Write(buf[:])
+		}
+	}
+	for _, yi := range y {
+		binary.LittleEndian.PutUint64(buf[:], uint64(int64(yi)))
+		h.Write(buf[:])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NewTrainingManifest builds the manifest of a model already trained on X, y.
+// Training hyperparameters are only filled for *SoftmaxRegression and *MLP.
+func NewTrainingManifest(c Classifier, X *mat.Dense, y []int, accuracy float64) TrainingManifest {
+	nSamples, nFeatures := X.Dims()
+	manifest := TrainingManifest{
+		CreatedAt:   time.Now().UTC(),
+		ModelType:   c.ModelType(),
+		DatasetHash: HashDataset(X, y),
+		NSamples:    nSamples,
+		NFeatures:   nFeatures,
+		NClasses:    countClasses(y),
+		Accuracy:    accuracy,
+	}
+
+	if m, ok := c.(*SoftmaxRegression); ok {
+		if m.W != nil {
+			_, manifest.NClasses = m.W.Dims()
+		}
+		if len(m.LossHistory) > 0 {
+			manifest.FinalLoss = m.LossHistory[len(m.LossHistory)-1]
+		}
+		manifest.Seed = m.Seed
+		manifest.Lr = m.Lr
+		manifest.NIter = m.NIter
+		manifest.RegLambda = m.RegLambda
+		manifest.Penalty = m.Penalty
+		manifest.L1Ratio = m.L1Ratio
+	}
+	if m, ok := c.(*MLP); ok {
+		if len(m.LossHistory) > 0 {
+			manifest.FinalLoss = m.LossHistory[len(m.LossHistory)-1]
+		}
+		manifest.Seed = m.Seed
+		manifest.Lr = m.Lr
+		manifest.NIter = m.NIter
+		manifest.RegLambda = m.RegLambda
+		manifest.Hidden = m.Hidden
+	}
+	return manifest
+}
+
+// SaveToFile writes the manifest as JSON.
+func (t TrainingManifest) SaveToFile(path string) error {
+	bytes, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, bytes)
+}
@@ -0,0 +1,352 @@
+package algorithms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// MLP is a multilayer perceptron classifier with one or two ReLU hidden
+// layers and a softmax output, trained with full-batch gradient descent.
+type MLP struct {
+	Hidden      []int   // Units per hidden layer (1 or 2 layers)
+	Lr          float64 // Learning Rate
+	NIter       int     // Number of iterations
+	RegLambda   float64 // L2 regularization strength
+	Seed        int64   // Seed for weight initialization
+	LossHistory []float64
+
+	Weights []*mat.Dense    // Weights[l]: (in_l x out_l)
+	Biases  []*mat.VecDense // Biases[l]: (out_l)
+	Stats   *TrainingStats  // Training-set statistics, set by Fit (see DriftMonitor)
+}
+
+// NewMLP creates an untrained network with the given hidden layer sizes.
+func NewMLP(hidden []int, lr float64, nIter int, regLambda float64) *MLP {
+	return &MLP{
+		Hidden:    hidden,
+		Lr:        lr,
+		NIter:     nIter,
+		RegLambda: regLambda,
+		Seed:      DefaultSeed,
+	}
+}
+
+// ModelType implements Classifier.
+func (n *MLP) ModelType() string {
+	return ModelMLP
+}
+
+// TrainingStats implements StatsCarrier.
+func (n *MLP) TrainingStats() *TrainingStats {
+	return n.Stats
+}
+
+// nFeatures returns the input size of a trained network.
+func (n *MLP) nFeatures() int {
+	if len(n.Weights) == 0 {
+		return 0
+	}
+	r, _ := n.Weights[0].Dims()
+	return r
+}
+
+// initWeights uses He initialization, suited for ReLU layers.
+func (n *MLP) initWeights(nFeatures, nClasses int) {
+	rng := rand.New(rand.NewSource(n.Seed))
+	sizes := append([]int{nFeatures}, n.Hidden...)
+	sizes = append(sizes, nClasses)
+
+	n.Weights = make([]*mat.Dense, len(sizes)-1)
+	n.Biases = make([]*mat.VecDense, len(sizes)-1)
+	for l := 0; l < len(sizes)-1; l++ {
+		in, out := sizes[l], sizes[l+1]
+		scale := math.Sqrt(2.0 / float64(in))
+		data := make([]float64, in*out)
+		for i := range data {
+			data[i] = scale * rng.NormFloat64()
+		}
+		n.Weights[l] = mat.NewDense(in, out, data)
+		n.Biases[l] = mat.NewVecDense(out, nil)
+	}
+}
+
+// forward returns the activations of every layer: acts[0] = X,
+// acts[l+1] = ReLU(acts[l] W_l + b_l) for hidden layers and the softmax
+// probabilities for the last one.
+func (n *MLP) forward(X *mat.Dense) []*mat.Dense {
+	acts := []*mat.Dense{X}
+	for l, W := range n.Weights {
+		nSamples, _ := acts[l].Dims()
+		_, out := W.Dims()
+		z := mat.NewDense(nSamples, out, nil)
+		z.Mul(acts[l], W)
+		for i := 0; i < nSamples; i++ {
+			row := z.RawRowView(i)
+			for k := range row {
+				row[k] += n.Biases[l].AtVec(k)
+			}
+		}
+
+		if l == len(n.Weights)-1 {
+			acts = append(acts, softmaxRows(z))
+			break
+		}
+		z.Apply(func(_, _ int, v float64) float64 { return math.Max(0, v) }, z)
+		acts = append(acts, z)
+	}
+	return acts
+}
+
+// validateHidden checks that the network has 1 or 2 hidden layers of
+// positive size.
+func (n *MLP) validateHidden() error {
+	if len(n.Hidden) < 1 || len(n.Hidden) > 2 {
+		return fmt.Errorf("MLP.Fit: 1 or 2 hidden layers are supported, got %d: %w", len(n.Hidden), ErrInvalidParam)
+	}
+	for _, h := range n.Hidden {
+		if h <= 0 {
+			return fmt.Errorf("MLP.Fit: hidden layer sizes must be positive: %w", ErrInvalidParam)
+		}
+	}
+	return nil
+}
+
+// Fit trains the network on X (n x d) and y (n,) with backpropagation.
+func (n *MLP) Fit(X *mat.Dense, y []int) error {
+	return n.FitContext(context.Background(), X, y, nil)
+}
+
+// FitContext is Fit with cancellation between iterations and per-iteration
+// progress (see ContextFitter).
+func (n *MLP) FitContext(ctx context.Context, X *mat.Dense, y []int, progress ProgressFunc) error {
+	if err := n.validateHidden(); err != nil {
+		return err
+	}
+	if err := validateMatrix("MLP.Fit", X, n.nFeatures()); err != nil {
+		return err
+	}
+	nSamples, nFeatures := X.Dims()
+	if err := validateLabels("MLP.Fit", y, nSamples, 0); err != nil {
+		return err
+	}
+	nClasses := countClasses(y)
+	if len(n.Weights) > 0 {
+		_, out := n.Weights[len(n.Weights)-1].Dims()
+		if nClasses > out {
+			return fmt.Errorf("MLP.Fit: label %d outside trained classes: %w", nClasses-1, ErrInvalidLabel)
+		}
+		nClasses = out
+	} else {
+		n.initWeights(nFeatures, nClasses)
+	}
+
+	n.Stats = NewTrainingStats(X, y, nil)
+	Y := oneHotDense(y, nSamples, nClasses)
+	n.LossHistory = nil
+
+	for iter := 0; iter < n.NIter; iter++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("MLP.Fit: stopped at iteration %d: %w", iter, err)
+		}
+		acts := n.forward(X)
+		probs := acts[len(acts)-1]
+
+		// cross-entropy + L2 on all weight matrices
+		loss := 0.0
+		for i := 0; i < nSamples; i++ {
+			p := probs.At(i, y[i])
+			if p < 1e-15 {
+				p = 1e-15
+			}
+			loss -= math.Log(p)
+		}
+		loss /= float64(nSamples)
+		for _, W := range n.Weights {
+			loss += penaltyLoss(W, 0, n.RegLambda)
+		}
+		n.LossHistory = append(n.LossHistory, loss)
+
+		// delta of the output layer: (probs - Y)/n
+		delta := mat.NewDense(nSamples, nClasses, nil)
+		delta.Sub(probs, Y)
+		delta.Scale(1.0/float64(nSamples), delta)
+
+		for l := len(n.Weights) - 1; l >= 0; l-- {
+			in, out := n.Weights[l].Dims()
+
+			dW := mat.NewDense(in, out, nil)
+			dW.Mul(acts[l].T(), delta)
+			if n.RegLambda > 0 {
+				var regW mat.Dense
+				regW.Scale(n.RegLambda, n.Weights[l])
+				dW.Add(dW, &regW)
+			}
+			db := make([]float64, out)
+			for i := 0; i < nSamples; i++ {
+				for k, v := range delta.RawRowView(i) {
+					db[k] += v
+				}
+			}
+
+			// propagate before updating W_l
+			if l > 0 {
+				prev := mat.NewDense(nSamples, in, nil)
+				prev.Mul(delta, n.Weights[l].T())
+				// ReLU derivative: only units that were active pass the gradient
+				prev.Apply(func(i, j int, v float64) float64 {
+					if acts[l].At(i, j) <= 0 {
+						return 0
+					}
+					return v
+				}, prev)
+				delta = prev
+			}
+
+			dW.Scale(n.Lr, dW)
+			n.Weights[l].Sub(n.Weights[l], dW)
+			for k := range db {
+				n.Biases[l].SetVec(k, n.Biases[l].AtVec(k)-n.Lr*db[k])
+			}
+		}
+
+		if progress != nil {
+			progress(Progress{Iteration: iter + 1, NIter: n.NIter, Loss: loss})
+		}
+	}
+	return nil
+}
+
+// PredictProba returns an (n x K) matrix with probabilities.
+func (n *MLP) PredictProba(X *mat.Dense) (*mat.Dense, error) {
+	if len(n.Weights) == 0 {
+		return nil, fmt.Errorf("MLP.PredictProba: %w", ErrNotTrained)
+	}
+	if err := validateMatrix("MLP.PredictProba", X, n.nFeatures()); err != nil {
+		return nil, err
+	}
+	acts := n.forward(X)
+	return acts[len(acts)-1], nil
+}
+
+// Predict returns argmax class index for each row.
+func (n *MLP) Predict(X *mat.Dense) ([]int, error) {
+	probs, err := n.PredictProba(X)
+	if err != nil {
+		return nil, err
+	}
+	return argmaxRows(probs), nil
+}
+
+// ===== Model persistence to disk =====
+
+// mlpLayerFile stores one layer like softmaxModelFile stores W and B.
+type mlpLayerFile struct {
+	NIn  int       `json:"n_in"`
+	NOut int       `json:"n_out"`
+	W    []float64 `json:"w"`
+	B    []float64 `json:"b"`
+}
+
+type mlpModelFile struct {
+	ModelType     string         `json:"model_type"`
+	NFeatures     int            `json:"n_features"`
+	NClasses      int            `json:"n_classes"`
+	Hidden        []int          `json:"hidden"`
+	Lr            float64        `json:"lr"`
+	NIter         int            `json:"n_iter"`
+	RegLambda     float64        `json:"reg_lambda"`
+	Seed          int64          `json:"seed"`
+	Layers        []mlpLayerFile `json:"layers"`
+	TrainingStats *TrainingStats `json:"training_stats,omitempty"`
+}
+
+// SaveToFile saves weights and biases of every layer to a JSON file.
+func (n *MLP) SaveToFile(path string) error {
+	fileStruct, err := n.artifact()
+	if err != nil {
+		return err
+	}
+	return writeModelJSON(path, fileStruct)
+}
+
+// artifact builds the file struct written by SaveToFile.
+func (n *MLP) artifact() (any, error) {
+	if len(n.Weights) == 0 {
+		return nil, fmt.Errorf("SaveToFile: %w", ErrNotTrained)
+	}
+	fileStruct := mlpModelFile{
+		ModelType:     ModelMLP,
+		NFeatures:     n.nFeatures(),
+		Hidden:        n.Hidden,
+		Lr:            n.Lr,
+		NIter:         n.NIter,
+		RegLambda:     n.RegLambda,
+		Seed:          n.Seed,
+		TrainingStats: n.Stats,
+	}
+	for l, W := range n.Weights {
+		in, out := W.Dims()
+		fileStruct.Layers = append(fileStruct.Layers, mlpLayerFile{
+			NIn:  in,
+			NOut: out,
+			W:    denseData(W),
+			B:    append([]float64(nil), n.Biases[l].RawVector().Data...),
+		})
+		fileStruct.NClasses = out
+	}
+	return fileStruct, nil
+}
+
+// LoadMLP loads a network from a JSON file.
+func LoadMLP(path string) (*MLP, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeMLP(bytes)
+}
+
+// decodeMLP builds a network from the JSON of its artifact.
+func decodeMLP(bytes []byte) (*MLP, error) {
+	var fileStruct mlpModelFile
+	if err := json.Unmarshal(bytes, &fileStruct); err != nil {
+		return nil, err
+	}
+	if len(fileStruct.Layers) != len(fileStruct.Hidden)+1 {
+		return nil, fmt.Errorf("LoadMLP: expected %d layers, got %d", len(fileStruct.Hidden)+1, len(fileStruct.Layers))
+	}
+
+	n := &MLP{
+		Hidden:    fileStruct.Hidden,
+		Lr:        fileStruct.Lr,
+		NIter:     fileStruct.NIter,
+		RegLambda: fileStruct.RegLambda,
+		Seed:      fileStruct.Seed,
+		Stats:     fileStruct.TrainingStats,
+	}
+	if err := fileStruct.TrainingStats.check("LoadMLP", fileStruct.NFeatures); err != nil {
+		return nil, err
+	}
+	prevOut := fileStruct.NFeatures
+	for l, layer := range fileStruct.Layers {
+		if layer.NIn != prevOut || layer.NIn*layer.NOut == 0 || len(layer.W) != layer.NIn*layer.NOut {
+			return nil, fmt.Errorf("LoadMLP: layer %d W dimensions mismatch", l)
+		}
+		if len(layer.B) != layer.NOut {
+			return nil, fmt.Errorf("LoadMLP: layer %d B dimensions mismatch", l)
+		}
+		n.Weights = append(n.Weights, mat.NewDense(layer.NIn, layer.NOut, layer.W))
+		n.Biases = append(n.Biases, mat.NewVecDense(layer.NOut, layer.B))
+		prevOut = layer.NOut
+	}
+	if prevOut != fileStruct.NClasses {
+		return nil, fmt.Errorf("LoadMLP: output layer has %d units, expected %d classes", prevOut, fileStruct.NClasses)
+	}
+	return n, nil
+}
@@ -0,0 +1,237 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// GaussianNB is a Gaussian naive Bayes classifier: every feature is modeled
+// as an independent normal distribution per class.
+type GaussianNB struct {
+	VarSmoothing float64 // Added to every variance, relative to the largest one
+
+	Priors []float64      // (nClasses)
+	Means  *mat.Dense     // (nClasses x nFeatures)
+	Vars   *mat.Dense     // (nClasses x nFeatures)
+	Stats  *TrainingStats // Training-set statistics, set by Fit (see DriftMonitor)
+}
+
+// NewGaussianNB creates an untrained model with the default smoothing.
+func NewGaussianNB() *GaussianNB {
+	return &GaussianNB{VarSmoothing: 1e-9}
+}
+
+// ModelType implements Classifier.
+func (g *GaussianNB) ModelType() string {
+	return ModelNaiveBayes
+}
+
+// TrainingStats implements StatsCarrier.
+func (g *GaussianNB) TrainingStats() *TrainingStats {
+	return g.Stats
+}
+
+// Fit estimates priors, means and variances of every class.
+func (g *GaussianNB) Fit(X *mat.Dense, y []int) error {
+	if err := validateMatrix("GaussianNB.Fit", X, 0); err != nil {
+		return err
+	}
+	nSamples, nFeatures := X.Dims()
+	if err := validateLabels("GaussianNB.Fit", y, nSamples, 0); err != nil {
+		return err
+	}
+	nClasses := countClasses(y)
+	g.Stats = NewTrainingStats(X, y, nil)
+
+	counts := make([]float64, nClasses)
+	means := mat.NewDense(nClasses, nFeatures, nil)
+	vars := mat.NewDense(nClasses, nFeatures, nil)
+
+	for i := 0; i < nSamples; i++ {
+		counts[y[i]]++
+		row := means.RawRowView(y[i])
+		for j, v := range X.RawRowView(i) {
+			row[j] += v
+		}
+	}
+	for k := 0; k < nClasses; k++ {
+		if counts[k] == 0 {
+			continue
+		}
+		row := means.RawRowView(k)
+		for j := range row {
+			row[j] /= counts[k]
+		}
+	}
+
+	maxVar := 0.0
+	for i := 0; i < nSamples; i++ {
+		mean := means.RawRowView(y[i])
+		row := vars.RawRowView(y[i])
+		for j, v := range X.RawRowView(i) {
+			d := v - mean[j]
+			row[j] += d * d
+		}
+	}
+	for k := 0; k < nClasses; k++ {
+		row := vars.RawRowView(k)
+		for j := range row {
+			if counts[k] > 0 {
+				row[j] /= counts[k]
+			}
+			maxVar = math.Max(maxVar, row[j])
+		}
+	}
+
+	// smoothing keeps constant features from producing zero variances
+	eps := g.VarSmoothing * math.Max(maxVar, 1)
+	for k := 0; k < nClasses; k++ {
+		row := vars.RawRowView(k)
+		for j := range row {
+			row[j] += eps
+		}
+	}
+
+	g.Priors = make([]float64, nClasses)
+	for k := range counts {
+		g.Priors[k] = counts[k] / float64(nSamples)
+	}
+	g.Means = means
+	g.Vars = vars
+	return nil
+}
+
+// PredictProba returns an (n x K) matrix with the posterior probabilities.
+func (g *GaussianNB) PredictProba(X *mat.Dense) (*mat.Dense, error) {
+	if g.Means == nil || g.Vars == nil {
+		return nil, fmt.Errorf("GaussianNB.PredictProba: %w", ErrNotTrained)
+	}
+	nClasses, nFeatures := g.Means.Dims()
+	if err := validateMatrix("GaussianNB.PredictProba", X, nFeatures); err != nil {
+		return nil, err
+	}
+	nSamples, _ := X.Dims()
+
+	// log joint likelihood, normalized afterwards with softmaxRows
+	scores := mat.NewDense(nSamples, nClasses, nil)
+	for i := 0; i < nSamples; i++ {
+		x := X.RawRowView(i)
+		for k := 0; k < nClasses; k++ {
+			if g.Priors[k] == 0 {
+				scores.Set(i, k, math.Inf(-1))
+				continue
+			}
+			s := math.Log(g.Priors[k])
+			mean := g.Means.RawRowView(k)
+			vr := g.Vars.RawRowView(k)
+			for j, v := range x {
+				d := v - mean[j]
+				s -= 0.5*math.Log(2*math.Pi*vr[j]) + d*d/(2*vr[j])
+			}
+			scores.Set(i, k, s)
+		}
+	}
+	return softmaxRows(scores), nil
+}
+
+// Predict returns the class with the highest posterior.
+func (g *GaussianNB) Predict(X *mat.Dense) ([]int, error) {
+	probs, err := g.PredictProba(X)
+	if err != nil {
+		return nil, err
+	}
+	return argmaxRows(probs), nil
+}
+
+// ===== Model persistence to disk =====
+
+type gaussianNBFile struct {
+	ModelType     string         `json:"model_type"`
+	NFeatures     int            `json:"n_features"`
+	NClasses      int            `json:"n_classes"`
+	VarSmoothing  float64        `json:"var_smoothing"`
+	Priors        []float64      `json:"priors"`
+	Means         []float64      `json:"means"`
+	Vars          []float64      `json:"vars"`
+	TrainingStats *TrainingStats `json:"training_stats,omitempty"`
+}
+
+// SaveToFile saves the model to a JSON file.
+func (g *GaussianNB) SaveToFile(path string) error {
+	fileStruct, err := g.artifact()
+	if err != nil {
+		return err
+	}
+	return writeModelJSON(path, fileStruct)
+}
+
+// artifact builds the file struct written by SaveToFile.
+func (g *GaussianNB) artifact() (any, error) {
+	if g.Means == nil || g.Vars == nil {
+		return nil, fmt.Errorf("SaveToFile: %w", ErrNotTrained)
+	}
+	nClasses, nFeatures := g.Means.Dims()
+	return gaussianNBFile{
+		ModelType:     ModelNaiveBayes,
+		NFeatures:     nFeatures,
+		NClasses:      nClasses,
+		VarSmoothing:  g.VarSmoothing,
+		Priors:        g.Priors,
+		Means:         denseData(g.Means),
+		Vars:          denseData(g.Vars),
+		TrainingStats: g.Stats,
+	}, nil
+}
+
+// LoadGaussianNB loads a model from a JSON file.
+func LoadGaussianNB(path string) (*GaussianNB, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeGaussianNB(bytes)
+}
+
+// decodeGaussianNB builds a model from the JSON of its artifact.
+func decodeGaussianNB(bytes []byte) (*GaussianNB, error) {
+	var fileStruct gaussianNBFile
+	if err := json.Unmarshal(bytes, &fileStruct); err != nil {
+		return nil, err
+	}
+	size := fileStruct.NClasses * fileStruct.NFeatures
+	if size == 0 || len(fileStruct.Means) != size || len(fileStruct.Vars) != size {
+		return nil, fmt.Errorf("LoadGaussianNB: means/vars dimensions mismatch")
+	}
+	if len(fileStruct.Priors) != fileStruct.NClasses {
+		return nil, fmt.Errorf("LoadGaussianNB: priors dimensions mismatch")
+	}
+	for _, v := range fileStruct.Vars {
+		if v <= 0 {
+			return nil, fmt.Errorf("LoadGaussianNB: variances must be positive")
+		}
+	}
+	if err := fileStruct.TrainingStats.check("LoadGaussianNB", fileStruct.NFeatures); err != nil {
+		return nil, err
+	}
+	return &GaussianNB{
+		VarSmoothing: fileStruct.VarSmoothing,
+		Priors:       fileStruct.Priors,
+		Means:        mat.NewDense(fileStruct.NClasses, fileStruct.NFeatures, fileStruct.Means),
+		Vars:         mat.NewDense(fileStruct.NClasses, fileStruct.NFeatures, fileStruct.Vars),
+		Stats:        fileStruct.TrainingStats,
+	}, nil
+}
+
+// denseData copies the values of m in row-major order.
+func denseData(m *mat.Dense) []float64 {
+	r, c := m.Dims()
+	out := make([]float64, 0, r*c)
+	for i := 0; i < r; i++ {
+		out = append(out, m.RawRowView(i)...)
+	}
+	return out
+}
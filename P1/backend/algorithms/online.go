@@ -0,0 +1,113 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// PartialFitConfig bounds a warm-start update (see PartialFit). The total
+// change of the weights is at most Steps * MaxStepNorm.
+type PartialFitConfig struct {
+	Steps       int     `json:"steps"`         // gradient steps on the batch
+	Lr          float64 `json:"lr"`            // 0 = the model's Lr
+	MaxStepNorm float64 `json:"max_step_norm"` // max change of (W, B) per step, 0 = unbounded
+}
+
+// DefaultPartialFitConfig returns a small update: a few steps, each one
+// moving the weights by at most 0.05.
+func DefaultPartialFitConfig() PartialFitConfig {
+	return PartialFitConfig{
+		Steps:       20,
+		MaxStepNorm: 0.05,
+	}
+}
+
+// PartialFitReport describes an update done by PartialFit.
+type PartialFitReport struct {
+	NSamples   int     `json:"n_samples"`
+	Steps      int     `json:"steps"`
+	LossBefore float64 `json:"loss_before"` // loss on the batch before the update
+	LossAfter  float64 `json:"loss_after"`
+	UpdateNorm float64 `json:"update_norm"` // Frobenius norm of the change of (W, B)
+}
+
+// PartialFit continues training a fitted model on the labeled batch X, y:
+// cfg.Steps gradient steps starting from the current weights, each one
+// bounded by cfg.MaxStepNorm. Unlike Fit, it keeps the classes of W (labels
+// must already exist), appends to LossHistory and leaves Stats as they are,
+// so drift is still measured against the original training set.
+//
+// PartialFit does not check that the model got better; run it on a Clone
+// and compare both on validation data (see EvaluateCandidate) before
+// replacing the model.
+func (m *SoftmaxRegression) PartialFit(X *mat.Dense, y []int, cfg PartialFitConfig) (PartialFitReport, error) {
+	if m.W == nil || m.B == nil {
+		return PartialFitReport{}, fmt.Errorf("PartialFit: %w", ErrNotTrained)
+	}
+	if err := m.validateX("PartialFit", X); err != nil {
+		return PartialFitReport{}, err
+	}
+	nSamples, _ := X.Dims()
+	_, nClasses := m.W.Dims()
+	if err := validateLabels("PartialFit", y, nSamples, nClasses); err != nil {
+		return PartialFitReport{}, err
+	}
+	if cfg.Steps <= 0 {
+		return PartialFitReport{}, fmt.Errorf("PartialFit: steps must be positive, got %d: %w", cfg.Steps, ErrInvalidParam)
+	}
+	if cfg.Lr < 0 || cfg.MaxStepNorm < 0 {
+		return PartialFitReport{}, fmt.Errorf("PartialFit: lr and max_step_norm must be non-negative: %w", ErrInvalidParam)
+	}
+	lr := cfg.Lr
+	if lr == 0 {
+		lr = m.Lr
+	}
+	if lr <= 0 {
+		return PartialFitReport{}, fmt.Errorf("PartialFit: learning rate must be positive: %w", ErrInvalidParam)
+	}
+	l1, l2, err := m.penaltyStrengths()
+	if err != nil {
+		return PartialFitReport{}, fmt.Errorf("PartialFit: %w", err)
+	}
+
+	var W0 mat.Dense
+	W0.CloneFrom(m.W)
+	B0 := mat.VecDenseCopyOf(m.B)
+
+	Y := oneHotDense(y, nSamples, nClasses)
+	report := PartialFitReport{NSamples: nSamples, Steps: cfg.Steps}
+	for step := 0; step < cfg.Steps; step++ {
+		loss := m.gradientStep(X, Y, lr, l1, l2, cfg.MaxStepNorm)
+		if step == 0 {
+			report.LossBefore = loss
+		}
+		m.LossHistory = append(m.LossHistory, loss)
+	}
+	report.LossAfter = m.loss(X, Y, l1, l2)
+
+	var dW mat.Dense
+	dW.Sub(m.W, &W0)
+	var dB mat.VecDense
+	dB.SubVec(m.B, B0)
+	report.UpdateNorm = math.Hypot(mat.Norm(&dW, 2), mat.Norm(&dB, 2))
+	return report, nil
+}
+
+// Clone returns a deep copy of the model. Stats are shared: they are not
+// modified after Fit.
+func (m *SoftmaxRegression) Clone() *SoftmaxRegression {
+	c := *m
+	c.rng = nil
+	if m.W != nil {
+		c.W = mat.DenseCopyOf(m.W)
+	}
+	if m.B != nil {
+		c.B = mat.VecDenseCopyOf(m.B)
+	}
+	c.LossHistory = append([]float64(nil), m.LossHistory...)
+	c.Thresholds = append([]float64(nil), m.Thresholds...)
+	c.FeatureNames = append([]string(nil), m.FeatureNames...)
+	return &c
+}
@@ -0,0 +1,94 @@
+package algorithms
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Progress is reported after every training iteration of an iterative model.
+type Progress struct {
+	Iteration int     `json:"iteration"` // 1-based
+	NIter     int     `json:"n_iter"`
+	Loss      float64 `json:"loss"`
+
+	// Validation metrics, set by ValidationProgress when it has a
+	// validation set.
+	ValLoss     *float64 `json:"val_loss,omitempty"`
+	ValAccuracy *float64 `json:"val_accuracy,omitempty"`
+}
+
+// ProgressFunc receives the progress of a training run. It is called from
+// the goroutine running Fit, between iterations, so it may read the model.
+type ProgressFunc func(Progress)
+
+// ContextFitter is implemented by the iterative models (softmax, MLP): the
+// training stops between iterations when ctx is done and reports every
+// iteration to progress (which may be nil).
+type ContextFitter interface {
+	FitContext(ctx context.Context, X *mat.Dense, y []int, progress ProgressFunc) error
+}
+
+// FitContext trains c with cancellation and progress when c supports it.
+// Other models train with Fit and only check ctx before starting. A
+// cancelled run returns an error wrapping ctx.Err().
+func FitContext(ctx context.Context, c Classifier, X *mat.Dense, y []int, progress ProgressFunc) error {
+	if cf, ok := c.(ContextFitter); ok {
+		return cf.FitContext(ctx, X, y, progress)
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("Fit: %w", err)
+	}
+	return c.Fit(X, y)
+}
+
+// DefaultProgressPoints is how many checkpoints ValidationProgress reports
+// per training run when every <= 0.
+const DefaultProgressPoints = 200
+
+// ValidationProgress returns a ProgressFunc for c that forwards to progress
+// only every `every` iterations and on the last one, adding the loss and
+// accuracy of c on the validation set X, y (skipped when X is nil). With
+// every <= 0 it reports DefaultProgressPoints checkpoints.
+func ValidationProgress(c Classifier, X *mat.Dense, y []int, every int, progress ProgressFunc) ProgressFunc {
+	return func(p Progress) {
+		step := every
+		if step <= 0 {
+			step = max(1, p.NIter/DefaultProgressPoints)
+		}
+		if p.Iteration%step != 0 && p.Iteration != p.NIter {
+			return
+		}
+		if X != nil && len(y) > 0 {
+			if loss, acc, err := validationMetrics(c, X, y); err == nil {
+				p.ValLoss, p.ValAccuracy = &loss, &acc
+			}
+		}
+		progress(p)
+	}
+}
+
+// validationMetrics returns the mean cross-entropy and the accuracy of c on
+// X, y. The accuracy uses Predict, so it includes the decision thresholds.
+func validationMetrics(c Classifier, X *mat.Dense, y []int) (float64, float64, error) {
+	probs, err := c.PredictProba(X)
+	if err != nil {
+		return 0, 0, err
+	}
+	acc, err := Accuracy(c, X, y)
+	if err != nil {
+		return 0, 0, err
+	}
+	_, nClasses := probs.Dims()
+	loss := 0.0
+	for i, yi := range y {
+		p := 1e-15
+		if yi < nClasses {
+			p = math.Max(probs.At(i, yi), 1e-15)
+		}
+		loss -= math.Log(p)
+	}
+	return loss / float64(len(y)), acc, nil
+}
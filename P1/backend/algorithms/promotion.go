@@ -0,0 +1,189 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+const DefaultGatesPath = "./weights/promotion_gates.json"
+
+// PromotionGates are the metric checks a candidate model must pass on the
+// held-out set to be promoted without an admin.
+type PromotionGates struct {
+	// AutoPromote promotes candidates that pass every gate. When false (the
+	// default) every candidate waits for an admin.
+	AutoPromote bool `json:"auto_promote"`
+	// ValidationPath is the held-out dataset, kept by the server, that
+	// candidates are evaluated on. It must not come from whoever trains the
+	// candidate. Without it no candidate passes the gates.
+	ValidationPath string `json:"validation_path,omitempty"`
+	// MinAccuracy is the minimum held-out accuracy of the candidate.
+	MinAccuracy float64 `json:"min_accuracy"`
+	// MaxAccuracyDrop is how much lower than the current model the candidate
+	// accuracy may be (0 = not worse).
+	MaxAccuracyDrop float64 `json:"max_accuracy_drop"`
+	// MinRecall is the minimum held-out recall per class index, e.g. to never
+	// promote a model that misses "alta" urgency cases.
+	MinRecall map[int]float64 `json:"min_recall,omitempty"`
+}
+
+// DefaultPromotionGates is used when there is no gates file.
+func DefaultPromotionGates() PromotionGates {
+	return PromotionGates{
+		MinAccuracy:     0.8,
+		MaxAccuracyDrop: 0,
+	}
+}
+
+// LoadPromotionGates reads the gates from a JSON file, or returns
+// DefaultPromotionGates if the file does not exist. Missing keys keep their
+// default value.
+func LoadPromotionGates(path string) (PromotionGates, error) {
+	gates := DefaultPromotionGates()
+	bytes, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return gates, nil
+	}
+	if err != nil {
+		return gates, err
+	}
+	if err := json.Unmarshal(bytes, &gates); err != nil {
+		return gates, fmt.Errorf("promotion gates %s: %w", path, err)
+	}
+	return gates, nil
+}
+
+// ModelMetrics are the held-out metrics of one model.
+type ModelMetrics struct {
+	Accuracy float64   `json:"accuracy"`
+	Recall   []float64 `json:"recall"` // per class index
+}
+
+// CandidateReport is the comparison of a candidate with the current model.
+type CandidateReport struct {
+	HoldoutSize int           `json:"holdout_size"`
+	Candidate   ModelMetrics  `json:"candidate"`
+	Current     *ModelMetrics `json:"current,omitempty"` // nil without a comparable current model
+	Passed      bool          `json:"passed"`
+	Failures    []string      `json:"failures,omitempty"`
+}
+
+// EvaluateMetrics computes accuracy and per-class recall of c on X, y.
+func EvaluateMetrics(c Classifier, X *mat.Dense, y []int) (ModelMetrics, error) {
+	yPred, err := c.Predict(X)
+	if err != nil {
+		return ModelMetrics{}, err
+	}
+	if len(yPred) != len(y) {
+		return ModelMetrics{}, fmt.Errorf("EvaluateMetrics: %d predictions and %d labels: %w", len(yPred), len(y), ErrDimensionMismatch)
+	}
+	nClasses := countClasses(y)
+	hits := make([]int, nClasses)
+	support := make([]int, nClasses)
+	correct := 0
+	for i := range y {
+		support[y[i]]++
+		if yPred[i] == y[i] {
+			hits[y[i]]++
+			correct++
+		}
+	}
+	metrics := ModelMetrics{
+		Accuracy: float64(correct) / float64(len(y)),
+		Recall:   make([]float64, nClasses),
+	}
+	for k := range support {
+		if support[k] > 0 {
+			metrics.Recall[k] = float64(hits[k]) / float64(support[k])
+		}
+	}
+	return metrics, nil
+}
+
+// EvaluateCandidate compares candidate with current (which may be nil) on the
+// held-out X, y and checks the gates. X must have the features of the
+// served model: a candidate or current model that cannot predict on it, or
+// a candidate with another number of classes than current, fails the gates.
+func EvaluateCandidate(candidate, current Classifier, X *mat.Dense, y []int, gates PromotionGates) (CandidateReport, error) {
+	report := CandidateReport{HoldoutSize: len(y)}
+	if len(y) == 0 {
+		report.Failures = append(report.Failures, "no held-out data to evaluate the candidate (validation_path)")
+		return report, nil
+	}
+	incompatible, err := checkCompatible(candidate, current, X)
+	if err != nil {
+		return report, err
+	}
+	if incompatible != "" {
+		report.Failures = append(report.Failures, incompatible)
+		return report, nil
+	}
+
+	report.Candidate, err = EvaluateMetrics(candidate, X, y)
+	if err != nil {
+		return report, err
+	}
+	if current != nil {
+		m, err := EvaluateMetrics(current, X, y)
+		if err != nil {
+			return report, err
+		}
+		report.Current = &m
+	}
+
+	if report.Candidate.Accuracy < gates.MinAccuracy {
+		report.Failures = append(report.Failures,
+			fmt.Sprintf("accuracy %.4f below min_accuracy %.4f", report.Candidate.Accuracy, gates.MinAccuracy))
+	}
+	if report.Current != nil && report.Candidate.Accuracy < report.Current.Accuracy-gates.MaxAccuracyDrop {
+		report.Failures = append(report.Failures,
+			fmt.Sprintf("accuracy %.4f worse than current model %.4f (max_accuracy_drop %.4f)",
+				report.Candidate.Accuracy, report.Current.Accuracy, gates.MaxAccuracyDrop))
+	}
+	for class, minRecall := range gates.MinRecall {
+		recall := 0.0
+		if class >= 0 && class < len(report.Candidate.Recall) {
+			recall = report.Candidate.Recall[class]
+		}
+		if recall < minRecall {
+			report.Failures = append(report.Failures,
+				fmt.Sprintf("recall of class %d %.4f below %.4f", class, recall, minRecall))
+		}
+	}
+	report.Passed = len(report.Failures) == 0
+	return report, nil
+}
+
+// checkCompatible returns why candidate cannot replace current, or "" if it
+// can: the candidate (or current) cannot predict on X, which has the
+// features of the served model, or the two have a different number of
+// classes. A mismatch is never treated as "no current model".
+func checkCompatible(candidate, current Classifier, X *mat.Dense) (string, error) {
+	probs, err := candidate.PredictProba(X)
+	if IsInputError(err) {
+		return fmt.Sprintf("candidate cannot predict the validation set: %v", err), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		return "", nil
+	}
+	currentProbs, err := current.PredictProba(X)
+	if IsInputError(err) {
+		return fmt.Sprintf("current model cannot predict the validation set: %v", err), nil
+	}
+	if err != nil {
+		return "", err
+	}
+	_, nClasses := probs.Dims()
+	_, currentClasses := currentProbs.Dims()
+	if nClasses != currentClasses {
+		return fmt.Sprintf("candidate has %d classes and the current model %d", nClasses, currentClasses), nil
+	}
+	return "", nil
+}
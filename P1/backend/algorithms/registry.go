@@ -0,0 +1,441 @@
+package algorithms
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const DefaultRegistryDir = "./weights/registry"
+
+// ErrVersionNotFound is returned for a model id that is not in the registry.
+var ErrVersionNotFound = errors.New("model version not found")
+
+// Registry stores every trained model as an immutable version:
+//
+//	<Dir>/<id>/model.json       the artifact
+//	<Dir>/<id>/model.json.sig   its SHA-256 and, once signed (Sign), signature
+//	<Dir>/<id>/manifest.json    the TrainingManifest (metrics, dataset hash)
+//	<Dir>/<id>/evaluation.json  the CandidateReport, if it was evaluated
+//	<Dir>/active.json           the active id and the previously active ones
+//
+// All files are written atomically (temp file + rename).
+type Registry struct {
+	Dir string
+	// ActivePath and ActiveManifestPath, when set, receive a copy of the
+	// active artifact and manifest on every promotion or rollback, for tools
+	// that read a fixed path.
+	ActivePath         string
+	ActiveManifestPath string
+	// SigningKey, when set, is the key of Sign. Register never signs: a
+	// version is signed only on a path allowed to activate it (an admin
+	// promotion), so with VerifyKey set an unsigned candidate cannot be
+	// activated. VerifyKey, when set, makes Load, Promote and Rollback refuse
+	// versions without a valid signature.
+	SigningKey ed25519.PrivateKey
+	VerifyKey  ed25519.PublicKey
+
+	mu sync.Mutex
+}
+
+// ModelVersion describes one registered model.
+type ModelVersion struct {
+	ID          string    `json:"id"`
+	CreatedAt   time.Time `json:"created_at"`
+	ModelType   string    `json:"model_type"`
+	Accuracy    float64   `json:"accuracy"`
+	DatasetHash string    `json:"dataset_sha256"`
+	NSamples    int       `json:"n_samples"`
+	Active      bool      `json:"active"`
+
+	Evaluation *CandidateReport `json:"evaluation,omitempty"`
+}
+
+// activeFile is the content of active.json. History is a stack of the ids
+// that were active before, the last one is the rollback target.
+type activeFile struct {
+	Active  string   `json:"active"`
+	History []string `json:"history"`
+}
+
+// NewRegistry returns a registry rooted at dir.
+func NewRegistry(dir string) *Registry {
+	return &Registry{Dir: dir}
+}
+
+// DefaultRegistry is the registry served by the API: DefaultRegistryDir,
+// mirroring the active model to DefaultSoftmaxModelPath and
+// DefaultManifestPath.
+func DefaultRegistry() *Registry {
+	return &Registry{
+		Dir:                DefaultRegistryDir,
+		ActivePath:         DefaultSoftmaxModelPath,
+		ActiveManifestPath: DefaultManifestPath,
+	}
+}
+
+func (r *Registry) modelPath(id string) string {
+	return filepath.Join(r.Dir, id, "model.json")
+}
+
+func (r *Registry) manifestPath(id string) string {
+	return filepath.Join(r.Dir, id, "manifest.json")
+}
+
+// Register saves c and its manifest as a new, unsigned version and returns
+// it. The new version is not active until Promote.
+func (r *Registry) Register(c Classifier, manifest TrainingManifest) (ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(r.Dir, 0o755); err != nil {
+		return ModelVersion{}, err
+	}
+	id, err := r.nextID()
+	if err != nil {
+		return ModelVersion{}, err
+	}
+	// Mkdir fails if another process took the same id
+	if err := os.Mkdir(filepath.Join(r.Dir, id), 0o755); err != nil {
+		return ModelVersion{}, fmt.Errorf("Register: %w", err)
+	}
+	if err := c.SaveToFile(r.modelPath(id)); err != nil {
+		os.RemoveAll(filepath.Join(r.Dir, id))
+		return ModelVersion{}, err
+	}
+	if err := manifest.SaveToFile(r.manifestPath(id)); err != nil {
+		os.RemoveAll(filepath.Join(r.Dir, id))
+		return ModelVersion{}, err
+	}
+	return versionOf(id, manifest, false), nil
+}
+
+// nextID returns "v0001", "v0002", ... after the highest existing id.
+func (r *Registry) nextID() (string, error) {
+	entries, err := os.ReadDir(r.Dir)
+	if err != nil {
+		return "", err
+	}
+	last := 0
+	for _, e := range entries {
+		if n, ok := parseID(e.Name()); ok && e.IsDir() && n > last {
+			last = n
+		}
+	}
+	return fmt.Sprintf("v%04d", last+1), nil
+}
+
+func parseID(id string) (int, bool) {
+	if !strings.HasPrefix(id, "v") {
+		return 0, false
+	}
+	n, err := strconv.Atoi(id[1:])
+	return n, err == nil && n > 0
+}
+
+func versionOf(id string, m TrainingManifest, active bool) ModelVersion {
+	return ModelVersion{
+		ID:          id,
+		CreatedAt:   m.CreatedAt,
+		ModelType:   m.ModelType,
+		Accuracy:    m.Accuracy,
+		DatasetHash: m.DatasetHash,
+		NSamples:    m.NSamples,
+		Active:      active,
+	}
+}
+
+// List returns every version, oldest first.
+func (r *Registry) List() ([]ModelVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, err := os.ReadDir(r.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	state, err := r.readActive()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []ModelVersion
+	for _, e := range entries {
+		if _, ok := parseID(e.Name()); !ok || !e.IsDir() {
+			continue
+		}
+		m, err := r.readManifest(e.Name())
+		if err != nil {
+			return nil, err
+		}
+		v := versionOf(e.Name(), m, e.Name() == state.Active)
+		if v.Evaluation, err = r.readEvaluation(e.Name()); err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		a, _ := parseID(versions[i].ID)
+		b, _ := parseID(versions[j].ID)
+		return a < b
+	})
+	return versions, nil
+}
+
+// Manifest returns the training manifest of a version.
+func (r *Registry) Manifest(id string) (TrainingManifest, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.readManifest(id)
+}
+
+func (r *Registry) readManifest(id string) (TrainingManifest, error) {
+	var m TrainingManifest
+	if _, ok := parseID(id); !ok {
+		return m, fmt.Errorf("%q: %w", id, ErrVersionNotFound)
+	}
+	bytes, err := os.ReadFile(r.manifestPath(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return m, fmt.Errorf("%q: %w", id, ErrVersionNotFound)
+	}
+	if err != nil {
+		return m, err
+	}
+	if err := json.Unmarshal(bytes, &m); err != nil {
+		return m, fmt.Errorf("manifest of %s: %w", id, err)
+	}
+	return m, nil
+}
+
+// SaveEvaluation stores the candidate report of a version.
+func (r *Registry) SaveEvaluation(id string, report CandidateReport) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.readManifest(id); err != nil {
+		return err
+	}
+	bytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(r.Dir, id, "evaluation.json"), bytes)
+}
+
+// readEvaluation returns the candidate report of a version, or nil.
+func (r *Registry) readEvaluation(id string) (*CandidateReport, error) {
+	bytes, err := os.ReadFile(filepath.Join(r.Dir, id, "evaluation.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report CandidateReport
+	if err := json.Unmarshal(bytes, &report); err != nil {
+		return nil, fmt.Errorf("evaluation of %s: %w", id, err)
+	}
+	return &report, nil
+}
+
+// Load loads the artifact of a version, verified with VerifyKey.
+func (r *Registry) Load(id string) (Classifier, error) {
+	if _, ok := parseID(id); !ok {
+		return nil, fmt.Errorf("%q: %w", id, ErrVersionNotFound)
+	}
+	c, err := LoadVerifiedClassifier(r.modelPath(id), r.VerifyKey)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%q: %w", id, ErrVersionNotFound)
+	}
+	return c, err
+}
+
+// Active returns the id of the active version, or "" if none was promoted.
+func (r *Registry) Active() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, err := r.readActive()
+	return state.Active, err
+}
+
+// LoadActive loads the active version and returns it with its id.
+func (r *Registry) LoadActive() (Classifier, string, error) {
+	id, err := r.Active()
+	if err != nil {
+		return nil, "", err
+	}
+	if id == "" {
+		return nil, "", fmt.Errorf("no active model: %w", ErrVersionNotFound)
+	}
+	c, err := r.Load(id)
+	return c, id, err
+}
+
+// Sign signs the artifact of id with SigningKey; without a key it does
+// nothing. The artifact must have the sidecar written by Register and still
+// match its SHA-256, so an artifact modified or with its sidecar removed
+// since then is not signed. Call it only on paths allowed to activate the
+// version.
+func (r *Registry) Sign(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.readManifest(id); err != nil {
+		return err
+	}
+	if r.SigningKey == nil {
+		return nil
+	}
+	path := r.modelPath(id)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksum(path, data); err != nil {
+		return err
+	}
+	return signArtifact(path, data, r.SigningKey)
+}
+
+// Promote makes id the active version. The previous active version becomes
+// the rollback target.
+func (r *Registry) Promote(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, err := r.readManifest(id); err != nil {
+		return err
+	}
+	if err := VerifyArtifact(r.modelPath(id), r.VerifyKey); err != nil {
+		return err
+	}
+	state, err := r.readActive()
+	if err != nil {
+		return err
+	}
+	if state.Active == id {
+		return nil
+	}
+	if state.Active != "" {
+		state.History = append(state.History, state.Active)
+	}
+	state.Active = id
+	return r.writeActive(state)
+}
+
+// Previous returns the id that Rollback would activate.
+func (r *Registry) Previous() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, err := r.readActive()
+	if err != nil {
+		return "", err
+	}
+	if len(state.History) == 0 {
+		return "", fmt.Errorf("no previous version: %w", ErrVersionNotFound)
+	}
+	return state.History[len(state.History)-1], nil
+}
+
+// Rollback reactivates the version that was active before the current one
+// and returns its id.
+func (r *Registry) Rollback() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	state, err := r.readActive()
+	if err != nil {
+		return "", err
+	}
+	if len(state.History) == 0 {
+		return "", fmt.Errorf("Rollback: no previous version: %w", ErrVersionNotFound)
+	}
+	if err := VerifyArtifact(r.modelPath(state.History[len(state.History)-1]), r.VerifyKey); err != nil {
+		return "", err
+	}
+	state.Active = state.History[len(state.History)-1]
+	state.History = state.History[:len(state.History)-1]
+	if err := r.writeActive(state); err != nil {
+		return "", err
+	}
+	return state.Active, nil
+}
+
+func (r *Registry) readActive() (activeFile, error) {
+	var state activeFile
+	bytes, err := os.ReadFile(filepath.Join(r.Dir, "active.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(bytes, &state); err != nil {
+		return state, fmt.Errorf("active.json: %w", err)
+	}
+	return state, nil
+}
+
+// writeActive saves active.json and mirrors the active version to
+// ActivePath and ActiveManifestPath.
+func (r *Registry) writeActive(state activeFile) error {
+	bytes, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(filepath.Join(r.Dir, "active.json"), bytes); err != nil {
+		return err
+	}
+	if r.ActivePath != "" {
+		if err := mirrorSignature(r.modelPath(state.Active), r.ActivePath); err != nil {
+			return err
+		}
+	}
+	mirrors := []struct{ src, dst string }{
+		{r.modelPath(state.Active), r.ActivePath},
+		{r.manifestPath(state.Active), r.ActiveManifestPath},
+	}
+	for _, m := range mirrors {
+		if m.dst == "" {
+			continue
+		}
+		data, err := os.ReadFile(m.src)
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(m.dst, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mirrorSignature copies the sidecar of src to dst, or removes the sidecar
+// of dst if src has none. Between this and the copy of the artifact a
+// reader may see a sidecar that does not match; the load then fails with
+// ErrCorruptArtifact instead of serving an unverified model.
+func mirrorSignature(src, dst string) error {
+	data, err := os.ReadFile(SignaturePath(src))
+	if errors.Is(err, os.ErrNotExist) {
+		if err := os.Remove(SignaturePath(dst)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(SignaturePath(dst), data)
+}
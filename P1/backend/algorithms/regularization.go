@@ -0,0 +1,115 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Penalty types supported by SoftmaxRegression.
+const (
+	PenaltyL2         = "l2"
+	PenaltyL1         = "l1"
+	PenaltyElasticNet = "elasticnet"
+)
+
+// penaltyStrengths splits RegLambda into its L1 and L2 parts:
+// penalty(W) = l1 * ||W||_1 + 0.5 * l2 * ||W||_2^2
+func (m *SoftmaxRegression) penaltyStrengths() (l1, l2 float64, err error) {
+	switch m.Penalty {
+	case "", PenaltyL2:
+		return 0, m.RegLambda, nil
+	case PenaltyL1:
+		return m.RegLambda, 0, nil
+	case PenaltyElasticNet:
+		if m.L1Ratio < 0 || m.L1Ratio > 1 {
+			return 0, 0, fmt.Errorf("l1_ratio must be in [0, 1], got %g: %w", m.L1Ratio, ErrInvalidParam)
+		}
+		return m.RegLambda * m.L1Ratio, m.RegLambda * (1 - m.L1Ratio), nil
+	default:
+		return 0, 0, fmt.Errorf("unknown penalty %q: %w", m.Penalty, ErrInvalidParam)
+	}
+}
+
+// penaltyLoss returns the value of the regularization term for W.
+func penaltyLoss(W *mat.Dense, l1, l2 float64) float64 {
+	if l1 == 0 && l2 == 0 {
+		return 0
+	}
+	rows, _ := W.Dims()
+	sumAbs, sumSq := 0.0, 0.0
+	for i := 0; i < rows; i++ {
+		for _, w := range W.RawRowView(i) {
+			sumAbs += math.Abs(w)
+			sumSq += w * w
+		}
+	}
+	return l1*sumAbs + 0.5*l2*sumSq
+}
+
+// softThreshold applies the proximal operator of t*||W||_1 in place:
+// w = sign(w) * max(|w| - t, 0). Weights that cross zero become exactly 0.
+func softThreshold(W *mat.Dense, t float64) {
+	if t <= 0 {
+		return
+	}
+	rows, _ := W.Dims()
+	for i := 0; i < rows; i++ {
+		row := W.RawRowView(i)
+		for k, w := range row {
+			switch {
+			case w > t:
+				row[k] = w - t
+			case w < -t:
+				row[k] = w + t
+			default:
+				row[k] = 0
+			}
+		}
+	}
+}
+
+// FeatureImportance holds the weights of one feature for every class and its
+// overall importance (sum of absolute weights across classes).
+type FeatureImportance struct {
+	Feature    string    `json:"feature"`
+	Weights    []float64 `json:"weights"`
+	Importance float64   `json:"importance"`
+	Zeroed     bool      `json:"zeroed"`
+}
+
+// FeatureImportances returns one entry per feature, ranked by importance
+// (most important first). names labels the rows of W; when it is shorter
+// than the number of features the missing names are "x<j>".
+func (m *SoftmaxRegression) FeatureImportances(names []string) ([]FeatureImportance, error) {
+	if m.W == nil {
+		return nil, fmt.Errorf("FeatureImportances: %w", ErrNotTrained)
+	}
+	nFeatures, nClasses := m.W.Dims()
+
+	out := make([]FeatureImportance, nFeatures)
+	for j := 0; j < nFeatures; j++ {
+		name := fmt.Sprintf("x%d", j)
+		if j < len(names) {
+			name = names[j]
+		}
+		weights := make([]float64, nClasses)
+		copy(weights, m.W.RawRowView(j))
+
+		importance := 0.0
+		for _, w := range weights {
+			importance += math.Abs(w)
+		}
+		out[j] = FeatureImportance{
+			Feature:    name,
+			Weights:    weights,
+			Importance: importance,
+			Zeroed:     importance == 0,
+		}
+	}
+
+	sort.SliceStable(out, func(a, b int) bool { return out[a].Importance > out[b].Importance })
+	return out, nil
+}
@@ -0,0 +1,167 @@
+package algorithms
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// GridSpace lists the values tried for each hyperparameter in a grid search.
+type GridSpace struct {
+	Lr        []float64
+	NIter     []int
+	RegLambda []float64
+}
+
+// Params returns every combination of the grid.
+func (g GridSpace) Params() []HyperParams {
+	var out []HyperParams
+	for _, lr := range g.Lr {
+		for _, nIter := range g.NIter {
+			for _, reg := range g.RegLambda {
+				out = append(out, HyperParams{Lr: lr, NIter: nIter, RegLambda: reg})
+			}
+		}
+	}
+	return out
+}
+
+// RandomSpace gives the ranges sampled in a random search. Lr and RegLambda
+// are sampled log-uniformly, NIter uniformly.
+type RandomSpace struct {
+	LrMin, LrMax       float64
+	NIterMin, NIterMax int
+	RegMin, RegMax     float64
+}
+
+// Sample draws n hyperparameter combinations from the space.
+func (s RandomSpace) Sample(n int, seed int64) ([]HyperParams, error) {
+	if s.LrMin <= 0 || s.LrMax < s.LrMin {
+		return nil, fmt.Errorf("RandomSpace: invalid lr range [%g, %g]", s.LrMin, s.LrMax)
+	}
+	if s.RegMin <= 0 || s.RegMax < s.RegMin {
+		return nil, fmt.Errorf("RandomSpace: invalid reg_lambda range [%g, %g]", s.RegMin, s.RegMax)
+	}
+	if s.NIterMin <= 0 || s.NIterMax < s.NIterMin {
+		return nil, fmt.Errorf("RandomSpace: invalid n_iter range [%d, %d]", s.NIterMin, s.NIterMax)
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	logUniform := func(lo, hi float64) float64 {
+		return math.Exp(math.Log(lo) + rng.Float64()*(math.Log(hi)-math.Log(lo)))
+	}
+
+	out := make([]HyperParams, n)
+	for i := range out {
+		out[i] = HyperParams{
+			Lr:        logUniform(s.LrMin, s.LrMax),
+			NIter:     s.NIterMin + rng.Intn(s.NIterMax-s.NIterMin+1),
+			RegLambda: logUniform(s.RegMin, s.RegMax),
+		}
+	}
+	return out, nil
+}
+
+// SearchConfig controls how the trials of a search are evaluated.
+type SearchConfig struct {
+	Folds   int   // number of cross-validation folds
+	Workers int   // maximum number of trials running at the same time
+	Seed    int64 // seed for folds and weight initialization
+}
+
+// TrialResult is one row of the search leaderboard.
+type TrialResult struct {
+	Params       HyperParams `json:"params"`
+	MeanAccuracy float64     `json:"mean_accuracy"`
+	StdAccuracy  float64     `json:"std_accuracy"`
+	Err          string      `json:"error,omitempty"`
+}
+
+// Search cross-validates every combination in params using a bounded pool
+// of workers and returns the leaderboard sorted by mean accuracy (best
+// first). Failed trials are kept at the end with Err set.
+func Search(X *mat.Dense, y []int, params []HyperParams, cfg SearchConfig) ([]TrialResult, error) {
+	if len(params) == 0 {
+		return nil, fmt.Errorf("Search: no hyperparameters to try")
+	}
+	if err := validateMatrix("Search", X, 0); err != nil {
+		return nil, err
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	results := make([]TrialResult, len(params))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				mean, std, err := CrossValidate(X, y, params[i], cfg.Folds, cfg.Seed)
+				results[i] = TrialResult{Params: params[i], MeanAccuracy: mean, StdAccuracy: std}
+				if err != nil {
+					results[i].Err = err.Error()
+				}
+			}
+		}()
+	}
+	for i := range params {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Err == "") != (results[j].Err == "") {
+			return results[i].Err == ""
+		}
+		return results[i].MeanAccuracy > results[j].MeanAccuracy
+	})
+	if results[0].Err != "" {
+		return results, fmt.Errorf("Search: all trials failed, first error: %s", results[0].Err)
+	}
+	return results, nil
+}
+
+// WriteLeaderboardCSV writes the search results to a CSV.
+// Formato columnas: rank, lr, n_iter, reg_lambda, mean_accuracy, std_accuracy, error
+func WriteLeaderboardCSV(path string, results []TrialResult) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	if err := w.Write([]string{"rank", "lr", "n_iter", "reg_lambda", "mean_accuracy", "std_accuracy", "error"}); err != nil {
+		return err
+	}
+	for i, r := range results {
+		record := []string{
+			strconv.Itoa(i + 1),
+			strconv.FormatFloat(r.Params.Lr, 'g', -1, 64),
+			strconv.Itoa(r.Params.NIter),
+			strconv.FormatFloat(r.Params.RegLambda, 'g', -1, 64),
+			fmt.Sprintf("%f", r.MeanAccuracy),
+			fmt.Sprintf("%f", r.StdAccuracy),
+			r.Err,
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,13 +1,12 @@
 package algorithms
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
 	"os"
-	"time"
 
 	"gonum.org/v1/gonum/mat"
 )
@@ -16,23 +15,41 @@ const DefaultSoftmaxModelPath = "./weights/softmax_model.json"
 
 // SoftmaxRegression implements multinomial logistic regression (softmax).
 type SoftmaxRegression struct {
-	W         *mat.Dense    // (nFeatures x nClasses)
-	B         *mat.VecDense // (nClasses)
-	Lr        float64       // Learning Rate
-	NIter     int           // Number of iterations
-	RegLambda float64       // Regularization strength
-	LossHistory []float64   // Training loss per iteration
+	W            *mat.Dense     // (nFeatures x nClasses)
+	B            *mat.VecDense  // (nClasses)
+	Lr           float64        // Learning Rate
+	NIter        int            // Number of iterations
+	RegLambda    float64        // Regularization strength
+	Penalty      string         // "l2" (default), "l1" or "elasticnet"
+	L1Ratio      float64        // Share of RegLambda used as L1 in elasticnet
+	LossHistory  []float64      // Training loss per iteration
+	Thresholds   []float64      // Per-class escalation thresholds (0 = disabled), see decideClass
+	Seed         int64          // Seed for weight initialization
+	FeatureNames []string       // Names of the columns of X (optional)
+	Stats        *TrainingStats // Training-set statistics, set by Fit (see DriftMonitor)
+
+	rng *rand.Rand // per-model random source, created from Seed
 }
 
+// DefaultSeed is the seed used when none is given explicitly.
+const DefaultSeed int64 = 42
+
 // NewSoftmaxRegression creates a new model with hyperparameters.
 func NewSoftmaxRegression(lr float64, nIter int, regLambda float64) *SoftmaxRegression {
 	return &SoftmaxRegression{
 		Lr:        lr,
 		NIter:     nIter,
 		RegLambda: regLambda,
+		Seed:      DefaultSeed,
 	}
 }
 
+// resetRandom recreates the per-model random source from Seed.
+func (m *SoftmaxRegression) resetRandom() *rand.Rand {
+	m.rng = rand.New(rand.NewSource(m.Seed))
+	return m.rng
+}
+
 // oneHotDense builds Y in one-hot format: (nSamples x nClasses).
 func oneHotDense(y []int, nSamples, nClasses int) *mat.Dense {
 	Y := mat.NewDense(nSamples, nClasses, nil)
@@ -98,20 +115,35 @@ func (m *SoftmaxRegression) forward(X *mat.Dense) (*mat.Dense, *mat.Dense) {
 
 // Fit trains the model on X (n x d) and y (n,).
 // Entrenar el modelo
-func (m *SoftmaxRegression) Fit(X *mat.Dense, y []int) {
+func (m *SoftmaxRegression) Fit(X *mat.Dense, y []int) error {
+	return m.FitContext(context.Background(), X, y, nil)
+}
+
+// FitContext is Fit with cancellation between iterations and per-iteration
+// progress (see ContextFitter).
+func (m *SoftmaxRegression) FitContext(ctx context.Context, X *mat.Dense, y []int, progress ProgressFunc) error {
+	if err := m.validateX("Fit", X); err != nil {
+		return err
+	}
 	// X es el vector de entrada que nosotros tenemos
 	nSamples, nFeatures := X.Dims()
 	// n muestras y n features
-	if nSamples == 0 {
-		log.Fatal("Fit: X is empty")
+	wClasses := 0
+	if m.W != nil {
+		_, wClasses = m.W.Dims()
 	}
-	if len(y) != nSamples {
-		log.Fatal("Fit: X and y have different number of samples")
+	if err := validateLabels("Fit", y, nSamples, wClasses); err != nil {
+		return err
 	}
+	l1, l2, err := m.penaltyStrengths()
+	if err != nil {
+		return fmt.Errorf("Fit: %w", err)
+	}
+	m.Stats = NewTrainingStats(X, y, m.FeatureNames)
 
 	// number of classes = max(y) + 1
-	//
-	nClasses := 0
+	// (o las clases de W si el modelo ya tiene pesos)
+	nClasses := wClasses
 	for _, yi := range y {
 		if yi+1 > nClasses {
 			nClasses = yi + 1
@@ -127,12 +159,12 @@ func (m *SoftmaxRegression) Fit(X *mat.Dense, y []int) {
 	// B = Vector de Bias
 
 	// initialize W and B
-	rand.Seed(time.Now().UnixNano())
-
+	// la semilla es explícita (m.Seed) para que el entrenamiento sea reproducible
 	if m.W == nil {
+		rng := m.resetRandom()
 		dataW := make([]float64, nFeatures*nClasses)
 		for i := range dataW {
-			dataW[i] = 0.01 * rand.NormFloat64()
+			dataW[i] = 0.01 * rng.NormFloat64()
 		}
 		m.W = mat.NewDense(nFeatures, nClasses, dataW)
 	}
@@ -159,140 +191,220 @@ func (m *SoftmaxRegression) Fit(X *mat.Dense, y []int) {
 	// dW, db
 	// iter = epochs
 	for iter := 0; iter < m.NIter; iter++ {
-		_, probs := m.forward(X) // probs: (n x K)
-
-		// Compute cross-entropy loss with optional L2 regularization
-		loss := 0.0
-		for i := 0; i < nSamples; i++ {
-			pRow := probs.RawRowView(i)
-			yRow := Y.RawRowView(i)
-			for k := 0; k < nClasses; k++ {
-				if yRow[k] == 1.0 {
-					p := pRow[k]
-					if p < 1e-15 {
-						p = 1e-15
-					}
-					loss -= math.Log(p)
-				}
-			}
-		}
-		loss /= float64(nSamples)
-
-		if m.RegLambda > 0 {
-			rowsW, colsW := m.W.Dims()
-			regSum := 0.0
-			for i := 0; i < rowsW; i++ {
-				row := m.W.RawRowView(i)
-				for k := 0; k < colsW; k++ {
-					regSum += row[k] * row[k]
-				}
-			}
-			loss += 0.5 * m.RegLambda * regSum
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("Fit: stopped at iteration %d: %w", iter, err)
 		}
+		loss := m.gradientStep(X, Y, m.Lr, l1, l2, 0)
 		m.LossHistory = append(m.LossHistory, loss)
 
-		// dScores = (probs - Y)/n
-		dScores := mat.NewDense(nSamples, nClasses, nil)
-		dScores.Sub(probs, Y)
-		dScores.Scale(1.0/float64(nSamples), dScores)
+		if progress != nil {
+			progress(Progress{Iteration: iter + 1, NIter: m.NIter, Loss: loss})
+		}
+	}
+	return nil
+}
+
+// gradientStep does one full-batch gradient descent step on X and the
+// one-hot labels Y and returns the loss before the step. With maxStep > 0,
+// a step that would change (W, B) by more than maxStep (Frobenius norm) is
+// shortened to that length.
+func (m *SoftmaxRegression) gradientStep(X, Y *mat.Dense, lr, l1, l2, maxStep float64) float64 {
+	nSamples, nFeatures := X.Dims()
+	_, nClasses := Y.Dims()
+	_, probs := m.forward(X) // probs: (n x K)
 
-		// dW = X^T * dScores + lambda * W
-		var XT mat.Dense
-		XT.CloneFrom(X.T()) // (d x n)
+	// Compute cross-entropy loss with optional L2 regularization
+	loss := crossEntropy(probs, Y)
 
-		dW := mat.NewDense(nFeatures, nClasses, nil)
-		dW.Mul(&XT, dScores) // (d x n)*(n x K) = (d x K)
+	// Regularization term (L2, L1 or elastic-net)
+	loss += penaltyLoss(m.W, l1, l2)
 
-		if m.RegLambda > 0 {
-			var regW mat.Dense
-			regW.CloneFrom(m.W)
-			regW.Scale(m.RegLambda, &regW)
-			dW.Add(dW, &regW)
+	// dScores = (probs - Y)/n
+	dScores := mat.NewDense(nSamples, nClasses, nil)
+	dScores.Sub(probs, Y)
+	dScores.Scale(1.0/float64(nSamples), dScores)
+
+	// dW = X^T * dScores + l2 * W
+	// (the L1 part is applied after the step with soft-thresholding)
+	var XT mat.Dense
+	XT.CloneFrom(X.T()) // (d x n)
+
+	dW := mat.NewDense(nFeatures, nClasses, nil)
+	dW.Mul(&XT, dScores) // (d x n)*(n x K) = (d x K)
+
+	if l2 > 0 {
+		var regW mat.Dense
+		regW.CloneFrom(m.W)
+		regW.Scale(l2, &regW)
+		dW.Add(dW, &regW)
+	}
+
+	// db = row-wise sum of dScores
+	dbData := make([]float64, nClasses)
+	for i := 0; i < nSamples; i++ {
+		row := dScores.RawRowView(i)
+		for k := 0; k < nClasses; k++ {
+			dbData[k] += row[k]
 		}
+	}
+	db := mat.NewVecDense(nClasses, dbData)
 
-		// db = row-wise sum of dScores
-		dbData := make([]float64, nClasses)
-		for i := 0; i < nSamples; i++ {
-			row := dScores.RawRowView(i)
-			for k := 0; k < nClasses; k++ {
-				dbData[k] += row[k]
-			}
+	// a step longer than maxStep is shortened, keeping its direction
+	if maxStep > 0 {
+		norm := lr * math.Hypot(mat.Norm(dW, 2), mat.Norm(db, 2))
+		if norm > maxStep {
+			lr *= maxStep / norm
 		}
-		db := mat.NewVecDense(nClasses, dbData)
+	}
 
-		// update W and B
-		// W = W - lr * dW
-		var scaledDW mat.Dense
-		scaledDW.Scale(m.Lr, dW)
-		m.W.Sub(m.W, &scaledDW)
+	// update W and B
+	// W = W - lr * dW
+	var scaledDW mat.Dense
+	scaledDW.Scale(lr, dW)
+	m.W.Sub(m.W, &scaledDW)
+	// proximal step for L1: drives irrelevant weights to exactly 0
+	softThreshold(m.W, lr*l1)
+
+	var scaledDB mat.VecDense
+	scaledDB.ScaleVec(lr, db)
+	m.B.SubVec(m.B, &scaledDB)
+	return loss
+}
 
-		var scaledDB mat.VecDense
-		scaledDB.ScaleVec(m.Lr, db)
-		m.B.SubVec(m.B, &scaledDB)
+// crossEntropy is the mean negative log-likelihood of the one-hot labels Y
+// under probs.
+func crossEntropy(probs, Y *mat.Dense) float64 {
+	nSamples, nClasses := Y.Dims()
+	loss := 0.0
+	for i := 0; i < nSamples; i++ {
+		pRow := probs.RawRowView(i)
+		yRow := Y.RawRowView(i)
+		for k := 0; k < nClasses; k++ {
+			if yRow[k] == 1.0 {
+				p := pRow[k]
+				if p < 1e-15 {
+					p = 1e-15
+				}
+				loss -= math.Log(p)
+			}
+		}
 	}
+	return loss / float64(nSamples)
+}
+
+// loss is the training loss (cross-entropy plus penalty) on X, Y.
+func (m *SoftmaxRegression) loss(X, Y *mat.Dense, l1, l2 float64) float64 {
+	_, probs := m.forward(X)
+	return crossEntropy(probs, Y) + penaltyLoss(m.W, l1, l2)
 }
 
 // PredictProba returns an (n x K) matrix with probabilities.
-func (m *SoftmaxRegression) PredictProba(X *mat.Dense) *mat.Dense {
+func (m *SoftmaxRegression) PredictProba(X *mat.Dense) (*mat.Dense, error) {
 	if m.W == nil || m.B == nil {
-		log.Fatal("PredictProba: model not trained")
+		return nil, fmt.Errorf("PredictProba: %w", ErrNotTrained)
+	}
+	if err := m.validateX("PredictProba", X); err != nil {
+		return nil, err
 	}
 	_, probs := m.forward(X)
-	return probs
+	return probs, nil
 }
 
-// Predict returns argmax class index for each row.
-func (m *SoftmaxRegression) Predict(X *mat.Dense) []int {
-	probs := m.PredictProba(X)
-	nSamples, nClasses := probs.Dims()
+// Predict returns the class index for each row: argmax of the
+// probabilities, escalated to a higher class when Thresholds allow it.
+func (m *SoftmaxRegression) Predict(X *mat.Dense) ([]int, error) {
+	probs, err := m.PredictProba(X)
+	if err != nil {
+		return nil, err
+	}
+	nSamples, _ := probs.Dims()
 	yPred := make([]int, nSamples)
 
 	for i := 0; i < nSamples; i++ {
-		row := probs.RawRowView(i)
-		maxIdx := 0
-		maxVal := row[0]
-		for k := 1; k < nClasses; k++ {
-			if row[k] > maxVal {
-				maxVal = row[k]
-				maxIdx = k
-			}
+		yPred[i] = decideClass(probs.RawRowView(i), m.Thresholds)
+	}
+	return yPred, nil
+}
+
+// argmaxRow returns the index of the largest value in row.
+func argmaxRow(row []float64) int {
+	maxIdx := 0
+	maxVal := row[0]
+	for k := 1; k < len(row); k++ {
+		if row[k] > maxVal {
+			maxVal = row[k]
+			maxIdx = k
 		}
-		yPred[i] = maxIdx
 	}
-	return yPred
+	return maxIdx
 }
 
-// Accuracy computes the fraction of correct predictions.
-func (m *SoftmaxRegression) Accuracy(X *mat.Dense, y []int) float64 {
-	yPred := m.Predict(X)
-	if len(yPred) != len(y) {
-		log.Fatal("Accuracy: different lengths for predictions and labels")
-	}
-	correct := 0
-	for i := range y {
-		if yPred[i] == y[i] {
-			correct++
+// decideClass picks the argmax class, unless a higher class has a
+// probability at or above its threshold. The highest class that qualifies
+// wins. A threshold of 0 disables escalation to that class.
+//
+// Escalating only makes sense when a higher index means a higher urgency,
+// as with the urgencia codes of bronco_dataset.csv (0 = baja, 1 = mediana,
+// 2 = alta). Thresholds must not be set on models trained on other label
+// spaces, such as the disease classes of dataset.BroncoClasses, where 7 is
+// reflujo with urgency baja.
+func decideClass(row []float64, thresholds []float64) int {
+	pred := argmaxRow(row)
+	for k := len(row) - 1; k > pred; k-- {
+		if k < len(thresholds) && thresholds[k] > 0 && row[k] >= thresholds[k] {
+			return k
 		}
 	}
-	return float64(correct) / float64(len(y))
+	return pred
+}
+
+// Accuracy computes the fraction of correct predictions.
+func (m *SoftmaxRegression) Accuracy(X *mat.Dense, y []int) (float64, error) {
+	return Accuracy(m, X, y)
+}
+
+// ModelType implements Classifier.
+func (m *SoftmaxRegression) ModelType() string {
+	return ModelSoftmax
+}
+
+// TrainingStats implements StatsCarrier.
+func (m *SoftmaxRegression) TrainingStats() *TrainingStats {
+	return m.Stats
 }
 
 // ===== Model persistence to disk =====
 // ARTEFACTO.
 type softmaxModelFile struct {
-	NFeatures int       `json:"n_features"`
-	NClasses  int       `json:"n_classes"`
-	W         []float64 `json:"w"`
-	B         []float64 `json:"b"`
-	Lr        float64   `json:"lr"`
-	NIter     int       `json:"n_iter"`
-	RegLambda float64   `json:"reg_lambda"`
+	ModelType     string         `json:"model_type"`
+	NFeatures     int            `json:"n_features"`
+	NClasses      int            `json:"n_classes"`
+	W             []float64      `json:"w"`
+	B             []float64      `json:"b"`
+	Lr            float64        `json:"lr"`
+	NIter         int            `json:"n_iter"`
+	RegLambda     float64        `json:"reg_lambda"`
+	Thresholds    []float64      `json:"thresholds,omitempty"`
+	Seed          int64          `json:"seed"`
+	Penalty       string         `json:"penalty,omitempty"`
+	L1Ratio       float64        `json:"l1_ratio,omitempty"`
+	FeatureNames  []string       `json:"feature_names,omitempty"`
+	TrainingStats *TrainingStats `json:"training_stats,omitempty"`
 }
 
 // SaveToFile saves weights and biases to a JSON file.
 func (m *SoftmaxRegression) SaveToFile(path string) error {
+	fileStruct, err := m.artifact()
+	if err != nil {
+		return err
+	}
+	return writeModelJSON(path, fileStruct)
+}
+
+// artifact builds the file struct written by SaveToFile.
+func (m *SoftmaxRegression) artifact() (any, error) {
 	if m.W == nil || m.B == nil {
-		return fmt.Errorf("SaveToFile: model not trained")
+		return nil, fmt.Errorf("SaveToFile: %w", ErrNotTrained)
 	}
 
 	nFeatures, nClasses := m.W.Dims()
@@ -308,51 +420,80 @@ func (m *SoftmaxRegression) SaveToFile(path string) error {
 	}
 
 	fileStruct := softmaxModelFile{
-		NFeatures: nFeatures,
-		NClasses:  nClasses,
-		W:         dataW,
-		B:         dataB,
-		Lr:        m.Lr,
-		NIter:     m.NIter,
-		RegLambda: m.RegLambda,
-	}
-
-	bytes, err := json.MarshalIndent(fileStruct, "", "  ")
-	if err != nil {
-		return err
+		ModelType:     ModelSoftmax,
+		NFeatures:     nFeatures,
+		NClasses:      nClasses,
+		W:             dataW,
+		B:             dataB,
+		Lr:            m.Lr,
+		NIter:         m.NIter,
+		RegLambda:     m.RegLambda,
+		Thresholds:    m.Thresholds,
+		Seed:          m.Seed,
+		Penalty:       m.Penalty,
+		L1Ratio:       m.L1Ratio,
+		FeatureNames:  m.FeatureNames,
+		TrainingStats: m.Stats,
 	}
-
-	return os.WriteFile(path, bytes, 0o644)
+	return fileStruct, nil
 }
 
-// LoadSoftmaxRegression loads a model from a JSON file.
+// LoadSoftmaxRegression loads a model from a JSON file, checking its
+// checksum sidecar if there is one.
 func LoadSoftmaxRegression(path string) (*SoftmaxRegression, error) {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
+	if err := verifyArtifact(path, bytes, nil); err != nil {
+		return nil, err
+	}
+	return decodeSoftmaxRegression(bytes)
+}
 
+// decodeSoftmaxRegression builds a model from the JSON of its artifact.
+func decodeSoftmaxRegression(bytes []byte) (*SoftmaxRegression, error) {
 	var fileStruct softmaxModelFile
 	if err := json.Unmarshal(bytes, &fileStruct); err != nil {
 		return nil, err
 	}
+	return softmaxFromFile(fileStruct)
+}
 
+// softmaxFromFile checks the dimensions of an artifact and builds the model.
+func softmaxFromFile(fileStruct softmaxModelFile) (*SoftmaxRegression, error) {
 	if len(fileStruct.W) != fileStruct.NFeatures*fileStruct.NClasses {
 		return nil, fmt.Errorf("LoadSoftmaxRegression: W dimensions mismatch")
 	}
 	if len(fileStruct.B) != fileStruct.NClasses {
 		return nil, fmt.Errorf("LoadSoftmaxRegression: B dimensions mismatch")
 	}
+	if len(fileStruct.FeatureNames) != 0 && len(fileStruct.FeatureNames) != fileStruct.NFeatures {
+		return nil, fmt.Errorf("LoadSoftmaxRegression: feature_names dimensions mismatch")
+	}
+	if len(fileStruct.Thresholds) != 0 && len(fileStruct.Thresholds) != fileStruct.NClasses {
+		return nil, fmt.Errorf("LoadSoftmaxRegression: thresholds dimensions mismatch")
+	}
+
+	if err := fileStruct.TrainingStats.check("LoadSoftmaxRegression", fileStruct.NFeatures); err != nil {
+		return nil, err
+	}
 
 	W := mat.NewDense(fileStruct.NFeatures, fileStruct.NClasses, fileStruct.W)
 	B := mat.NewVecDense(fileStruct.NClasses, fileStruct.B)
 
 	model := &SoftmaxRegression{
-		W:         W,
-		B:         B,
-		Lr:        fileStruct.Lr,
-		NIter:     fileStruct.NIter,
-		RegLambda: fileStruct.RegLambda,
+		W:            W,
+		B:            B,
+		Lr:           fileStruct.Lr,
+		NIter:        fileStruct.NIter,
+		RegLambda:    fileStruct.RegLambda,
+		Thresholds:   fileStruct.Thresholds,
+		Seed:         fileStruct.Seed,
+		Penalty:      fileStruct.Penalty,
+		L1Ratio:      fileStruct.L1Ratio,
+		FeatureNames: fileStruct.FeatureNames,
+		Stats:        fileStruct.TrainingStats,
 	}
 	return model, nil
 }
@@ -387,7 +528,14 @@ func Example() {
 	// implementar
 	X := mat.NewDense(9, 2, Xdata)
 	model := NewSoftmaxRegression(0.1, 2000, 1e-3)
-	model.Fit(X, y)
-	acc := model.Accuracy(X, y)
+	if err := model.Fit(X, y); err != nil {
+		fmt.Println("fit error:", err)
+		return
+	}
+	acc, err := model.Accuracy(X, y)
+	if err != nil {
+		fmt.Println("accuracy error:", err)
+		return
+	}
 	fmt.Printf("training accuracy: %.4f\n", acc)
 }
@@ -0,0 +1,108 @@
+package algorithms
+
+import (
+	"fmt"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// ThresholdResult describes the threshold chosen for one class and the
+// metrics it obtains on the validation data used to tune it.
+type ThresholdResult struct {
+	Class     int     `json:"class"`
+	Threshold float64 `json:"threshold"`
+	Recall    float64 `json:"recall"`
+	Precision float64 `json:"precision"`
+}
+
+// TuneThreshold picks the escalation threshold for class so that its recall
+// on (X, y) reaches targetRecall. Among the thresholds that reach the
+// target, the highest one is chosen to keep false escalations low.
+// The other entries of m.Thresholds are kept as they are while tuning.
+// Classes must be ordered by urgency (see decideClass), and (X, y) must be
+// validation data the model was not trained on.
+func (m *SoftmaxRegression) TuneThreshold(X *mat.Dense, y []int, class int, targetRecall float64) (ThresholdResult, error) {
+	if m.W == nil || m.B == nil {
+		return ThresholdResult{}, fmt.Errorf("TuneThreshold: %w", ErrNotTrained)
+	}
+	if err := m.validateX("TuneThreshold", X); err != nil {
+		return ThresholdResult{}, err
+	}
+	nSamples, _ := X.Dims()
+	_, nClasses := m.W.Dims()
+	if err := validateLabels("TuneThreshold", y, nSamples, nClasses); err != nil {
+		return ThresholdResult{}, err
+	}
+	if class < 0 || class >= nClasses {
+		return ThresholdResult{}, fmt.Errorf("TuneThreshold: class %d out of range [0, %d)", class, nClasses)
+	}
+	if targetRecall <= 0 || targetRecall > 1 {
+		return ThresholdResult{}, fmt.Errorf("TuneThreshold: target recall must be in (0, 1]")
+	}
+
+	_, probs := m.forward(X)
+
+	// candidate thresholds: probabilities of the class on its positive samples
+	var candidates []float64
+	for i := 0; i < nSamples; i++ {
+		if y[i] == class {
+			candidates = append(candidates, probs.At(i, class))
+		}
+	}
+	if len(candidates) == 0 {
+		return ThresholdResult{}, fmt.Errorf("TuneThreshold: no validation samples with class %d", class)
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(candidates)))
+
+	thresholds := make([]float64, nClasses)
+	copy(thresholds, m.Thresholds)
+
+	for _, t := range candidates {
+		if t <= 0 {
+			break
+		}
+		thresholds[class] = t
+		recall, precision := classMetrics(probs, y, class, thresholds)
+		if recall >= targetRecall {
+			return ThresholdResult{Class: class, Threshold: t, Recall: recall, Precision: precision}, nil
+		}
+	}
+
+	return ThresholdResult{}, fmt.Errorf("TuneThreshold: target recall %.3f not reachable for class %d", targetRecall, class)
+}
+
+// classMetrics computes recall and precision of class using decideClass.
+func classMetrics(probs *mat.Dense, y []int, class int, thresholds []float64) (float64, float64) {
+	tp, fp, fn := 0, 0, 0
+	for i := range y {
+		pred := decideClass(probs.RawRowView(i), thresholds)
+		switch {
+		case pred == class && y[i] == class:
+			tp++
+		case pred == class:
+			fp++
+		case y[i] == class:
+			fn++
+		}
+	}
+	recall, precision := 0.0, 0.0
+	if tp+fn > 0 {
+		recall = float64(tp) / float64(tp+fn)
+	}
+	if tp+fp > 0 {
+		precision = float64(tp) / float64(tp+fp)
+	}
+	return recall, precision
+}
+
+// SetThreshold stores the threshold of class, allocating Thresholds if needed.
+func (m *SoftmaxRegression) SetThreshold(class int, threshold float64) {
+	_, nClasses := m.W.Dims()
+	if len(m.Thresholds) != nClasses {
+		thresholds := make([]float64, nClasses)
+		copy(thresholds, m.Thresholds)
+		m.Thresholds = thresholds
+	}
+	m.Thresholds[class] = threshold
+}
@@ -0,0 +1,281 @@
+package algorithms
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// DecisionTree is a CART classification tree split on Gini impurity.
+type DecisionTree struct {
+	MaxDepth        int // Maximum depth of the tree (root = 0)
+	MinSamplesSplit int // Minimum samples in a node to try a split
+
+	NFeatures int
+	NClasses  int
+	Nodes     []treeNode     // Nodes[0] is the root
+	Stats     *TrainingStats // Training-set statistics, set by Fit (see DriftMonitor)
+}
+
+// treeNode is stored in a flat slice so the tree serializes as plain JSON.
+// Leaves have Feature == -1 and hold the class probabilities of the node.
+type treeNode struct {
+	Feature   int       `json:"feature"`
+	Threshold float64   `json:"threshold"`
+	Left      int       `json:"left"`
+	Right     int       `json:"right"`
+	Probs     []float64 `json:"probs"`
+}
+
+// NewDecisionTree creates an untrained tree with hyperparameters.
+func NewDecisionTree(maxDepth, minSamplesSplit int) *DecisionTree {
+	return &DecisionTree{MaxDepth: maxDepth, MinSamplesSplit: minSamplesSplit}
+}
+
+// ModelType implements Classifier.
+func (t *DecisionTree) ModelType() string {
+	return ModelDecisionTree
+}
+
+// TrainingStats implements StatsCarrier.
+func (t *DecisionTree) TrainingStats() *TrainingStats {
+	return t.Stats
+}
+
+// Fit grows the tree on X (n x d) and y (n,).
+func (t *DecisionTree) Fit(X *mat.Dense, y []int) error {
+	if err := validateMatrix("DecisionTree.Fit", X, 0); err != nil {
+		return err
+	}
+	nSamples, nFeatures := X.Dims()
+	if err := validateLabels("DecisionTree.Fit", y, nSamples, 0); err != nil {
+		return err
+	}
+
+	t.NFeatures = nFeatures
+	t.NClasses = countClasses(y)
+	t.Nodes = nil
+	t.Stats = NewTrainingStats(X, y, nil)
+
+	idx := make([]int, nSamples)
+	for i := range idx {
+		idx[i] = i
+	}
+	t.grow(X, y, idx, 0)
+	return nil
+}
+
+// grow appends the node for the samples idx and returns its index.
+func (t *DecisionTree) grow(X *mat.Dense, y []int, idx []int, depth int) int {
+	counts := make([]float64, t.NClasses)
+	for _, i := range idx {
+		counts[y[i]]++
+	}
+	probs := make([]float64, t.NClasses)
+	for k := range counts {
+		probs[k] = counts[k] / float64(len(idx))
+	}
+
+	node := len(t.Nodes)
+	t.Nodes = append(t.Nodes, treeNode{Feature: -1, Probs: probs})
+
+	if depth >= t.MaxDepth || len(idx) < t.MinSamplesSplit || gini(counts, len(idx)) == 0 {
+		return node
+	}
+
+	feature, threshold, ok := t.bestSplit(X, y, idx)
+	if !ok {
+		return node
+	}
+
+	var left, right []int
+	for _, i := range idx {
+		if X.At(i, feature) <= threshold {
+			left = append(left, i)
+		} else {
+			right = append(right, i)
+		}
+	}
+
+	l := t.grow(X, y, left, depth+1)
+	r := t.grow(X, y, right, depth+1)
+	t.Nodes[node].Feature = feature
+	t.Nodes[node].Threshold = threshold
+	t.Nodes[node].Left = l
+	t.Nodes[node].Right = r
+	return node
+}
+
+// bestSplit finds the (feature, threshold) with the lowest weighted Gini.
+func (t *DecisionTree) bestSplit(X *mat.Dense, y []int, idx []int) (int, float64, bool) {
+	n := len(idx)
+	total := make([]float64, t.NClasses)
+	for _, i := range idx {
+		total[y[i]]++
+	}
+
+	// a split is only kept if it lowers the impurity of the node
+	bestScore := gini(total, n)
+	bestFeature, bestThreshold, found := -1, 0.0, false
+
+	sorted := make([]int, n)
+	for j := 0; j < t.NFeatures; j++ {
+		copy(sorted, idx)
+		sort.Slice(sorted, func(a, b int) bool { return X.At(sorted[a], j) < X.At(sorted[b], j) })
+
+		left := make([]float64, t.NClasses)
+		right := make([]float64, t.NClasses)
+		copy(right, total)
+
+		for s := 0; s < n-1; s++ {
+			i := sorted[s]
+			left[y[i]]++
+			right[y[i]]--
+
+			v, next := X.At(i, j), X.At(sorted[s+1], j)
+			if v == next {
+				continue
+			}
+			nl, nr := s+1, n-s-1
+			score := (float64(nl)*gini(left, nl) + float64(nr)*gini(right, nr)) / float64(n)
+			if score < bestScore {
+				bestScore = score
+				bestFeature = j
+				bestThreshold = (v + next) / 2
+				found = true
+			}
+		}
+	}
+	return bestFeature, bestThreshold, found
+}
+
+// gini returns the Gini impurity of class counts over n samples.
+func gini(counts []float64, n int) float64 {
+	if n == 0 {
+		return 0
+	}
+	g := 1.0
+	for _, c := range counts {
+		p := c / float64(n)
+		g -= p * p
+	}
+	return g
+}
+
+// PredictProba returns an (n x K) matrix with the class frequencies of the
+// leaf reached by every sample.
+func (t *DecisionTree) PredictProba(X *mat.Dense) (*mat.Dense, error) {
+	if len(t.Nodes) == 0 {
+		return nil, fmt.Errorf("DecisionTree.PredictProba: %w", ErrNotTrained)
+	}
+	if err := validateMatrix("DecisionTree.PredictProba", X, t.NFeatures); err != nil {
+		return nil, err
+	}
+	nSamples, _ := X.Dims()
+	out := mat.NewDense(nSamples, t.NClasses, nil)
+	for i := 0; i < nSamples; i++ {
+		node := t.Nodes[0]
+		for node.Feature >= 0 {
+			if X.At(i, node.Feature) <= node.Threshold {
+				node = t.Nodes[node.Left]
+			} else {
+				node = t.Nodes[node.Right]
+			}
+		}
+		out.SetRow(i, node.Probs)
+	}
+	return out, nil
+}
+
+// Predict returns the most frequent class of the leaf of every sample.
+func (t *DecisionTree) Predict(X *mat.Dense) ([]int, error) {
+	probs, err := t.PredictProba(X)
+	if err != nil {
+		return nil, err
+	}
+	return argmaxRows(probs), nil
+}
+
+// ===== Model persistence to disk =====
+
+type decisionTreeFile struct {
+	ModelType       string         `json:"model_type"`
+	NFeatures       int            `json:"n_features"`
+	NClasses        int            `json:"n_classes"`
+	MaxDepth        int            `json:"max_depth"`
+	MinSamplesSplit int            `json:"min_samples_split"`
+	Nodes           []treeNode     `json:"nodes"`
+	TrainingStats   *TrainingStats `json:"training_stats,omitempty"`
+}
+
+// SaveToFile saves the tree to a JSON file.
+func (t *DecisionTree) SaveToFile(path string) error {
+	fileStruct, err := t.artifact()
+	if err != nil {
+		return err
+	}
+	return writeModelJSON(path, fileStruct)
+}
+
+// artifact builds the file struct written by SaveToFile.
+func (t *DecisionTree) artifact() (any, error) {
+	if len(t.Nodes) == 0 {
+		return nil, fmt.Errorf("SaveToFile: %w", ErrNotTrained)
+	}
+	return decisionTreeFile{
+		ModelType:       ModelDecisionTree,
+		NFeatures:       t.NFeatures,
+		NClasses:        t.NClasses,
+		MaxDepth:        t.MaxDepth,
+		MinSamplesSplit: t.MinSamplesSplit,
+		Nodes:           t.Nodes,
+		TrainingStats:   t.Stats,
+	}, nil
+}
+
+// LoadDecisionTree loads a tree from a JSON file.
+func LoadDecisionTree(path string) (*DecisionTree, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return decodeDecisionTree(bytes)
+}
+
+// decodeDecisionTree builds a model from the JSON of its artifact.
+func decodeDecisionTree(bytes []byte) (*DecisionTree, error) {
+	var fileStruct decisionTreeFile
+	if err := json.Unmarshal(bytes, &fileStruct); err != nil {
+		return nil, err
+	}
+	if len(fileStruct.Nodes) == 0 {
+		return nil, fmt.Errorf("LoadDecisionTree: tree has no nodes")
+	}
+	nNodes := len(fileStruct.Nodes)
+	for i, n := range fileStruct.Nodes {
+		if len(n.Probs) != fileStruct.NClasses {
+			return nil, fmt.Errorf("LoadDecisionTree: probs dimensions mismatch")
+		}
+		if n.Feature >= fileStruct.NFeatures {
+			return nil, fmt.Errorf("LoadDecisionTree: node %d uses unknown feature %d", i, n.Feature)
+		}
+		// children are always stored after their parent, which also rules out cycles
+		if n.Feature >= 0 && (n.Left <= i || n.Right <= i || n.Left >= nNodes || n.Right >= nNodes) {
+			return nil, fmt.Errorf("LoadDecisionTree: node %d has invalid children", i)
+		}
+	}
+	if err := fileStruct.TrainingStats.check("LoadDecisionTree", fileStruct.NFeatures); err != nil {
+		return nil, err
+	}
+	return &DecisionTree{
+		MaxDepth:        fileStruct.MaxDepth,
+		MinSamplesSplit: fileStruct.MinSamplesSplit,
+		NFeatures:       fileStruct.NFeatures,
+		NClasses:        fileStruct.NClasses,
+		Nodes:           fileStruct.Nodes,
+		Stats:           fileStruct.TrainingStats,
+	}, nil
+}
@@ -0,0 +1,109 @@
+package algorithms
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Uncertainty measures how sure a classifier is about one prediction, from
+// its row of PredictProba and the class it decided.
+type Uncertainty struct {
+	Entropy float64 `json:"entropy"` // Shannon entropy divided by log(K): 0 = certain, 1 = uniform
+	// Margin is the probability of the decided class minus the most
+	// probable other class. It is negative when the decision is not the
+	// argmax (e.g. a class escalated by a decision threshold).
+	Margin      float64 `json:"margin"`
+	Class       int     `json:"class"` // the decided class
+	RunnerUp    int     `json:"runner_up_class"`
+	Probability float64 `json:"probability"` // probability of the decided class
+}
+
+// Priority orders cases for labeling: the mean of the entropy and of one
+// minus the margin (a negative margin counts as 0), both in [0, 1]. Higher
+// means less sure.
+func (u Uncertainty) Priority() float64 {
+	return (u.Entropy + 1 - math.Max(u.Margin, 0)) / 2
+}
+
+// RowUncertainty computes the uncertainty of one probability row when the
+// decision is its argmax. With a single class the prediction is certain.
+func RowUncertainty(probs []float64) Uncertainty {
+	if len(probs) == 0 {
+		return Uncertainty{RunnerUp: -1, Margin: 1}
+	}
+	return ClassUncertainty(probs, argmaxRow(probs))
+}
+
+// ClassUncertainty computes the uncertainty of deciding class from one
+// probability row. Use it when the decision is not the argmax, as with
+// SoftmaxRegression.Thresholds: the margin is measured around the decided
+// class, so it is negative if another class was more probable.
+func ClassUncertainty(probs []float64, class int) Uncertainty {
+	u := Uncertainty{Class: class, RunnerUp: -1, Margin: 1}
+	if class < 0 || class >= len(probs) {
+		return u
+	}
+	u.Probability = probs[class]
+	if len(probs) == 1 {
+		return u
+	}
+	for k, p := range probs {
+		if k != class && (u.RunnerUp == -1 || p > probs[u.RunnerUp]) {
+			u.RunnerUp = k
+		}
+		if p > 0 {
+			u.Entropy -= p * math.Log(p)
+		}
+	}
+	u.Entropy /= math.Log(float64(len(probs)))
+	u.Margin = probs[class] - probs[u.RunnerUp]
+	return u
+}
+
+// UncertaintyOf computes the uncertainty of every prediction of c on X,
+// around the class c.Predict decides.
+func UncertaintyOf(c Classifier, X *mat.Dense) ([]Uncertainty, error) {
+	probs, err := c.PredictProba(X)
+	if err != nil {
+		return nil, err
+	}
+	classes, err := c.Predict(X)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Uncertainty, len(classes))
+	for i, class := range classes {
+		out[i] = ClassUncertainty(probs.RawRowView(i), class)
+	}
+	return out, nil
+}
+
+// UncertaintyThresholds decide which predictions are uncertain enough to be
+// reviewed by a person.
+type UncertaintyThresholds struct {
+	MaxEntropy float64 `json:"max_entropy"` // uncertain above this normalized entropy
+	MinMargin  float64 `json:"min_margin"`  // uncertain below this top-2 margin
+}
+
+// DefaultUncertaintyThresholds flags predictions with more than 0.8 of the
+// maximum entropy or less than 0.2 between the two most probable classes.
+// With 3 classes, (0.7, 0.2, 0.1) is not flagged and (0.6, 0.3, 0.1) is.
+func DefaultUncertaintyThresholds() UncertaintyThresholds {
+	return UncertaintyThresholds{MaxEntropy: 0.8, MinMargin: 0.2}
+}
+
+// Validate checks that both thresholds are in [0, 1].
+func (t UncertaintyThresholds) Validate() error {
+	if t.MaxEntropy < 0 || t.MaxEntropy > 1 || t.MinMargin < 0 || t.MinMargin > 1 {
+		return fmt.Errorf("uncertainty thresholds must be in [0, 1]: %w", ErrInvalidParam)
+	}
+	return nil
+}
+
+// Uncertain reports whether u exceeds either threshold. A decision that is
+// not the most probable class (negative margin) is always uncertain.
+func (t UncertaintyThresholds) Uncertain(u Uncertainty) bool {
+	return u.Entropy > t.MaxEntropy || u.Margin < t.MinMargin
+}
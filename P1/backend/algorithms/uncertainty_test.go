@@ -0,0 +1,43 @@
+package algorithms
+
+import (
+	"math"
+	"testing"
+)
+
+func TestClassUncertaintyArgmax(t *testing.T) {
+	u := ClassUncertainty([]float64{0.1, 0.6, 0.3}, 1)
+	if u.Class != 1 || u.RunnerUp != 2 {
+		t.Errorf("class %d runner-up %d, want 1 and 2", u.Class, u.RunnerUp)
+	}
+	if math.Abs(u.Margin-0.3) > 1e-12 || u.Probability != 0.6 {
+		t.Errorf("margin %v probability %v, want 0.3 and 0.6", u.Margin, u.Probability)
+	}
+	if u != RowUncertainty([]float64{0.1, 0.6, 0.3}) {
+		t.Errorf("ClassUncertainty of the argmax differs from RowUncertainty")
+	}
+}
+
+// A decision threshold can escalate to a class that is not the most
+// probable: the margin is measured around that class and the argmax is the
+// runner-up.
+func TestClassUncertaintyNotArgmax(t *testing.T) {
+	probs := []float64{0.55, 0.15, 0.30}
+	u := ClassUncertainty(probs, 2)
+	if u.Class != 2 || u.Probability != 0.30 {
+		t.Errorf("class %d probability %v, want 2 and 0.30", u.Class, u.Probability)
+	}
+	if u.RunnerUp != 0 {
+		t.Errorf("runner-up %d, want the argmax 0", u.RunnerUp)
+	}
+	if math.Abs(u.Margin-(-0.25)) > 1e-12 {
+		t.Errorf("margin %v, want -0.25", u.Margin)
+	}
+	// a negative margin counts as no margin at all
+	if want := (u.Entropy + 1) / 2; u.Priority() != want {
+		t.Errorf("priority %v, want %v", u.Priority(), want)
+	}
+	if argmax := RowUncertainty(probs); u.Priority() <= argmax.Priority() {
+		t.Errorf("priority %v of the escalated class is not above %v of the argmax", u.Priority(), argmax.Priority())
+	}
+}
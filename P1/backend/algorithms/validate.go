@@ -0,0 +1,109 @@
+package algorithms
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Errors returned by the model API. They are wrapped with context, so
+// callers should compare them with errors.Is.
+var (
+	ErrEmptyInput        = errors.New("input is empty")
+	ErrNotTrained        = errors.New("model not trained")
+	ErrDimensionMismatch = errors.New("dimension mismatch")
+	ErrInvalidLabel      = errors.New("invalid label")
+	ErrNonFinite         = errors.New("input contains NaN or Inf")
+	ErrInvalidParam      = errors.New("invalid hyperparameter")
+)
+
+// IsInputError reports whether err was caused by bad input data or
+// hyperparameters (as opposed to an internal failure), so HTTP handlers can
+// answer with a 4xx status.
+func IsInputError(err error) bool {
+	return errors.Is(err, ErrEmptyInput) ||
+		errors.Is(err, ErrDimensionMismatch) ||
+		errors.Is(err, ErrInvalidLabel) ||
+		errors.Is(err, ErrNonFinite) ||
+		errors.Is(err, ErrInvalidParam)
+}
+
+// validateX checks that X is non-empty, finite and, when the model is
+// already trained, has as many columns as W has rows.
+func (m *SoftmaxRegression) validateX(op string, X *mat.Dense) error {
+	expected := 0
+	if m.W != nil {
+		expected, _ = m.W.Dims()
+	}
+	return validateMatrix(op, X, expected)
+}
+
+// validateMatrix checks that X is non-empty, finite and, when
+// nFeatures > 0, has exactly nFeatures columns.
+func validateMatrix(op string, X *mat.Dense, nFeatures int) error {
+	if X == nil {
+		return fmt.Errorf("%s: %w", op, ErrEmptyInput)
+	}
+	nSamples, cols := X.Dims()
+	if nSamples == 0 || cols == 0 {
+		return fmt.Errorf("%s: %w", op, ErrEmptyInput)
+	}
+	if nFeatures > 0 && cols != nFeatures {
+		return fmt.Errorf("%s: X has %d features, model expects %d: %w", op, cols, nFeatures, ErrDimensionMismatch)
+	}
+	for i := 0; i < nSamples; i++ {
+		for j, v := range X.RawRowView(i) {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return fmt.Errorf("%s: X[%d][%d]=%v: %w", op, i, j, v, ErrNonFinite)
+			}
+		}
+	}
+	return nil
+}
+
+// validateLabels checks that y matches the number of rows of X and that
+// every label is a valid class index. nClasses <= 0 means any non-negative
+// label is accepted (model without weights yet).
+func validateLabels(op string, y []int, nSamples, nClasses int) error {
+	if len(y) != nSamples {
+		return fmt.Errorf("%s: X has %d samples and y has %d: %w", op, nSamples, len(y), ErrDimensionMismatch)
+	}
+	for i, yi := range y {
+		if yi < 0 || (nClasses > 0 && yi >= nClasses) {
+			return fmt.Errorf("%s: y[%d]=%d: %w", op, i, yi, ErrInvalidLabel)
+		}
+	}
+	return nil
+}
+
+// ValidateFit returns the error c.Fit(X, y) would return for its
+// hyperparameters or the shape of X and y, without training. Use it to
+// reject a request before training it in the background.
+func ValidateFit(c Classifier, X *mat.Dense, y []int) error {
+	nFeatures, nClasses := 0, 0
+	switch m := c.(type) {
+	case *SoftmaxRegression:
+		if _, _, err := m.penaltyStrengths(); err != nil {
+			return fmt.Errorf("Fit: %w", err)
+		}
+		if m.W != nil {
+			nFeatures, nClasses = m.W.Dims()
+		}
+	case *MLP:
+		if err := m.validateHidden(); err != nil {
+			return err
+		}
+		nFeatures = m.nFeatures()
+	case *KNN:
+		if m.K <= 0 {
+			return fmt.Errorf("KNN.Fit: k must be positive, got %d: %w", m.K, ErrInvalidParam)
+		}
+	}
+	if err := validateMatrix("Fit", X, nFeatures); err != nil {
+		return err
+	}
+	nSamples, _ := X.Dims()
+	return validateLabels("Fit", y, nSamples, nClasses)
+}
@@ -0,0 +1,723 @@
+// Comando unmatch: CLI para entrenar, evaluar y servir los modelos sin pasar
+// por la API HTTP. Se ejecuta desde P1/backend:
+//
+//	go run ./cmd/unmatch <subcomando> [flags]
+//
+// Use "go run ./cmd/unmatch <subcomando> -h" para ver los flags de cada uno.
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+	"unmatch/backend/server"
+)
+
+// subcomando es una entrada del CLI.
+type subcomando struct {
+	nombre string
+	ayuda  string
+	run    func(args []string) error
+}
+
+var subcomandos = []subcomando{
+	{"train", "entrena un modelo con un dataset CSV/JSONL y lo guarda", cmdTrain},
+	{"evaluate", "evalúa un modelo guardado sobre un dataset", cmdEvaluate},
+	{"predict", "predice la clase de un vector de features", cmdPredict},
+	{"export", "valida un artefacto y lo escribe en otra ruta", cmdExport},
+	{"serve", "levanta la API HTTP", cmdServe},
+	{"thresholds", "ajusta el umbral de la clase alta para un recall objetivo", cmdThresholds},
+	{"search", "búsqueda de hiperparámetros del modelo softmax", cmdSearch},
+	{"compare", "compara todos los clasificadores con validación cruzada", cmdCompare},
+	{"ensemble", "combina modelos guardados en un ensamble", cmdEnsemble},
+	{"generate", "genera un dataset sintético con el esquema bronco", cmdGenerate},
+	{"corpus", "evalúa el pipeline completo sobre un corpus de textos etiquetados", cmdCorpus},
+	{"feedback", "exporta las correcciones de los clínicos como CSV de entrenamiento", cmdFeedback},
+	{"models", "lista, promueve o revierte versiones del registro (list | promote <id> | rollback)", cmdModels},
+	{"keygen", "genera un par de claves Ed25519 para firmar modelos", cmdKeygen},
+	{"sign", "firma un artefacto (escribe <artefacto>.sig)", cmdSign},
+	{"verify", "verifica el checksum y la firma de un artefacto", cmdVerify},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		uso()
+		os.Exit(2)
+	}
+	for _, sc := range subcomandos {
+		if sc.nombre == os.Args[1] {
+			if err := sc.run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, "error:", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	if os.Args[1] != "-h" && os.Args[1] != "help" {
+		fmt.Fprintf(os.Stderr, "subcomando desconocido %q\n\n", os.Args[1])
+	}
+	uso()
+	os.Exit(2)
+}
+
+func uso() {
+	fmt.Fprintln(os.Stderr, "uso: unmatch <subcomando> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, sc := range subcomandos {
+		fmt.Fprintf(os.Stderr, "  %-11s %s\n", sc.nombre, sc.ayuda)
+	}
+}
+
+// flagsDataset son los flags comunes para leer un dataset.
+type flagsDataset struct {
+	data   string
+	label  string
+	schema string
+}
+
+func (d *flagsDataset) registrar(fs *flag.FlagSet) {
+	fs.StringVar(&d.data, "data", datasetBroncoPath, "dataset CSV o JSONL")
+	fs.StringVar(&d.label, "label", "urgencia", "columna de etiqueta (si no se usa -schema)")
+	fs.StringVar(&d.schema, "schema", "", "esquema JSON del dataset (por defecto todas las columnas menos -label son features)")
+}
+
+// cargar lee el dataset con el esquema de -schema o, si no se indica, con
+// uno inferido del encabezado del CSV. Para JSONL sin esquema se usa el
+// esquema bronco con la etiqueta de -label.
+func (d *flagsDataset) cargar() (*dataset.Dataset, error) {
+	var schema dataset.Schema
+	var err error
+	switch {
+	case d.schema != "":
+		schema, err = dataset.LoadSchema(d.schema)
+	case strings.EqualFold(filepath.Ext(d.data), ".csv"):
+		schema, err = dataset.InferCSVSchema(d.data, d.label)
+	default:
+		schema = dataset.BroncoSchema()
+		schema.Label = d.label
+	}
+	if err != nil {
+		return nil, err
+	}
+	return dataset.Load(d.data, schema)
+}
+
+// parseFlags parsea args y rechaza argumentos posicionales sobrantes.
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("argumentos no reconocidos: %v", fs.Args())
+	}
+	return nil
+}
+
+// parseFloats convierte "1,2.5,3" en []float64.
+func parseFloats(s string) ([]float64, error) {
+	var out []float64
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido '%s'", p)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// parseInts convierte "16,8" en []int.
+func parseInts(s string) ([]int, error) {
+	var out []int
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return nil, fmt.Errorf("entero inválido '%s'", p)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// ===== train =====
+
+func cmdTrain(args []string) error {
+	fs := flag.NewFlagSet("train", flag.ExitOnError)
+	var d flagsDataset
+	d.registrar(fs)
+	modelo := fs.String("modelo", algorithms.ModelSoftmax, "tipo de modelo: "+strings.Join(algorithms.ModelTypes, ", "))
+	lr := fs.Float64("lr", 0, "learning rate (softmax, mlp; 0 = por defecto)")
+	nIter := fs.Int("n-iter", 0, "iteraciones (softmax, mlp; 0 = por defecto)")
+	reg := fs.Float64("reg-lambda", 0, "fuerza de regularización (softmax, mlp; 0 = por defecto)")
+	penalty := fs.String("penalty", "", "penalización softmax: l2, l1 o elasticnet")
+	l1Ratio := fs.Float64("l1-ratio", 0, "proporción L1 para elasticnet")
+	maxDepth := fs.Int("max-depth", 0, "profundidad máxima (decision_tree)")
+	minSplit := fs.Int("min-samples-split", 0, "mínimo de muestras para dividir (decision_tree)")
+	k := fs.Int("k", 0, "vecinos (knn)")
+	hidden := fs.String("hidden", "", "capas ocultas separadas por coma, p. ej. 16,8 (mlp)")
+	seed := fs.Int64("seed", algorithms.DefaultSeed, "semilla de inicialización")
+	out := fs.String("out", "", "guardar el artefacto en esta ruta en lugar de registrarlo como versión activa")
+	manifestPath := fs.String("manifest", "", "con -out, ruta del manifiesto (vacío = no guardar)")
+	lossPath := fs.String("loss", "./weights/softmax_bronco_loss.csv", "CSV con la curva de pérdida de softmax (vacío = no guardar)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	ds, err := d.cargar()
+	if err != nil {
+		return err
+	}
+
+	model, err := algorithms.NewClassifier(*modelo)
+	if err != nil {
+		return err
+	}
+	switch m := model.(type) {
+	case *algorithms.SoftmaxRegression:
+		if *lr > 0 {
+			m.Lr = *lr
+		}
+		if *nIter > 0 {
+			m.NIter = *nIter
+		}
+		if *reg > 0 {
+			m.RegLambda = *reg
+		}
+		m.Penalty = *penalty
+		m.L1Ratio = *l1Ratio
+		m.Seed = *seed
+		m.FeatureNames = ds.FeatureNames
+	case *algorithms.DecisionTree:
+		if *maxDepth > 0 {
+			m.MaxDepth = *maxDepth
+		}
+		if *minSplit > 0 {
+			m.MinSamplesSplit = *minSplit
+		}
+	case *algorithms.KNN:
+		if *k > 0 {
+			m.K = *k
+		}
+	case *algorithms.MLP:
+		if *hidden != "" {
+			h, err := parseInts(*hidden)
+			if err != nil {
+				return err
+			}
+			m.Hidden = h
+		}
+		if *lr > 0 {
+			m.Lr = *lr
+		}
+		if *nIter > 0 {
+			m.NIter = *nIter
+		}
+		if *reg > 0 {
+			m.RegLambda = *reg
+		}
+		m.Seed = *seed
+	}
+
+	nSamples, nFeatures := ds.X.Dims()
+	fmt.Printf("Entrenando %s con %s (%d muestras, %d features)...\n", model.ModelType(), d.data, nSamples, nFeatures)
+	if err := model.Fit(ds.X, ds.Y); err != nil {
+		return fmt.Errorf("error al entrenar el modelo: %w", err)
+	}
+	acc, err := algorithms.Accuracy(model, ds.X, ds.Y)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Accuracy entrenamiento: %.4f\n", acc)
+
+	manifest := algorithms.NewTrainingManifest(model, ds.X, ds.Y, acc)
+	manifest.DatasetPath = d.data
+	if *out == "" {
+		if err := registrarModelo(model, manifest); err != nil {
+			return err
+		}
+	} else {
+		if err := model.SaveToFile(*out); err != nil {
+			return fmt.Errorf("error al guardar el modelo: %w", err)
+		}
+		fmt.Println("Modelo guardado en", *out)
+		if *manifestPath != "" {
+			if err := manifest.SaveToFile(*manifestPath); err != nil {
+				return fmt.Errorf("error al guardar el manifiesto de entrenamiento: %w", err)
+			}
+		}
+	}
+	if sm, ok := model.(*algorithms.SoftmaxRegression); ok && *lossPath != "" && len(sm.LossHistory) > 0 {
+		if err := exportLossCSV(*lossPath, sm.LossHistory); err != nil {
+			return fmt.Errorf("error al exportar curva de pérdida: %w", err)
+		}
+		fmt.Println("Se generó:", *lossPath, "(iter, loss)")
+	}
+	return nil
+}
+
+// ===== evaluate =====
+
+func cmdEvaluate(args []string) error {
+	fs := flag.NewFlagSet("evaluate", flag.ExitOnError)
+	var d flagsDataset
+	d.registrar(fs)
+	modelPath := fs.String("model", algorithms.DefaultSoftmaxModelPath, "artefacto del modelo")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	model, err := algorithms.LoadClassifier(*modelPath)
+	if err != nil {
+		return fmt.Errorf("no se pudo cargar el modelo: %w", err)
+	}
+	ds, err := d.cargar()
+	if err != nil {
+		return err
+	}
+	yPred, err := model.Predict(ds.X)
+	if err != nil {
+		return err
+	}
+
+	nClasses := 0
+	for i := range ds.Y {
+		nClasses = max(nClasses, ds.Y[i]+1, yPred[i]+1)
+	}
+	// confusion[real][predicha]
+	confusion := make([][]int, nClasses)
+	for i := range confusion {
+		confusion[i] = make([]int, nClasses)
+	}
+	correct := 0
+	for i := range ds.Y {
+		confusion[ds.Y[i]][yPred[i]]++
+		if ds.Y[i] == yPred[i] {
+			correct++
+		}
+	}
+
+	fmt.Printf("Modelo %s sobre %s (%d muestras)\n", model.ModelType(), d.data, len(ds.Y))
+	fmt.Printf("Accuracy: %.4f\n\n", float64(correct)/float64(len(ds.Y)))
+	fmt.Println("clase  precision  recall  soporte")
+	for k := 0; k < nClasses; k++ {
+		tp, predK, soporte := confusion[k][k], 0, 0
+		for j := 0; j < nClasses; j++ {
+			predK += confusion[j][k]
+			soporte += confusion[k][j]
+		}
+		precision, recall := 0.0, 0.0
+		if predK > 0 {
+			precision = float64(tp) / float64(predK)
+		}
+		if soporte > 0 {
+			recall = float64(tp) / float64(soporte)
+		}
+		fmt.Printf("%5d  %9.4f  %6.4f  %7d\n", k, precision, recall, soporte)
+	}
+	fmt.Println("\nMatriz de confusión (filas = real, columnas = predicha):")
+	for _, fila := range confusion {
+		fmt.Println(fila)
+	}
+	return nil
+}
+
+// ===== predict =====
+
+func cmdPredict(args []string) error {
+	fs := flag.NewFlagSet("predict", flag.ExitOnError)
+	modelPath := fs.String("model", algorithms.DefaultSoftmaxModelPath, "artefacto del modelo")
+	x := fs.String("x", "", "vector de features separado por comas")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *x == "" {
+		return fmt.Errorf("-x es requerido")
+	}
+	vec, err := parseFloats(*x)
+	if err != nil {
+		return err
+	}
+
+	model, err := algorithms.LoadClassifier(*modelPath)
+	if err != nil {
+		return fmt.Errorf("no se pudo cargar el modelo: %w", err)
+	}
+	X := mat.NewDense(1, len(vec), vec)
+	probs, err := model.PredictProba(X)
+	if err != nil {
+		return err
+	}
+	pred, err := model.Predict(X)
+	if err != nil {
+		return err
+	}
+
+	salida, err := json.MarshalIndent(map[string]any{
+		"modelo":         model.ModelType(),
+		"clase":          pred[0],
+		"probabilidades": probs.RawRowView(0),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(salida))
+	return nil
+}
+
+// ===== export =====
+
+func cmdExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	modelPath := fs.String("model", algorithms.DefaultSoftmaxModelPath, "artefacto del modelo")
+	out := fs.String("out", "", "ruta de salida")
+	format := fs.String("format", algorithms.FormatJSON, "formato de salida: "+strings.Join(algorithms.ExportFormats, ", ")+" (binary y pmml solo softmax)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return fmt.Errorf("-out es requerido")
+	}
+
+	model, err := algorithms.LoadClassifier(*modelPath)
+	if err != nil {
+		return fmt.Errorf("no se pudo cargar el modelo: %w", err)
+	}
+	if err := algorithms.ExportModel(model, *out, *format); err != nil {
+		return fmt.Errorf("error al exportar el modelo: %w", err)
+	}
+	fmt.Printf("Modelo %s exportado en %s (%s)\n", model.ModelType(), *out, *format)
+	return nil
+}
+
+// ===== serve =====
+
+func cmdServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "dirección donde escuchar")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return server.Run(*addr)
+}
+
+// ===== utilidades del dataset bronco =====
+
+func cmdThresholds(args []string) error {
+	fs := flag.NewFlagSet("thresholds", flag.ExitOnError)
+	data := fs.String("data", "", "dataset de validación (obligatorio; no puede ser el de entrenamiento)")
+	recall := fs.Float64("recall", 0.95, "recall objetivo de la clase alta")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *data == "" {
+		return fmt.Errorf("falta -data: el umbral se ajusta sobre un dataset de validación separado del de entrenamiento")
+	}
+	return TuneThresholdsBronco(*data, *recall)
+}
+
+func cmdSearch(args []string) error {
+	fs := flag.NewFlagSet("search", flag.ExitOnError)
+	data := fs.String("data", datasetBroncoPath, "dataset de entrenamiento")
+	modo := fs.String("modo", "grid", "grid o random")
+	trials := fs.Int("trials", 20, "combinaciones a probar en modo random")
+	save := fs.Bool("save", false, "entrenar y guardar el mejor modelo")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return SearchSoftmaxBronco(*data, *modo, *trials, *save)
+}
+
+func cmdCompare(args []string) error {
+	fs := flag.NewFlagSet("compare", flag.ExitOnError)
+	data := fs.String("data", datasetBroncoPath, "dataset de entrenamiento")
+	save := fs.Bool("save", false, "entrenar y guardar el mejor clasificador")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	return CompararClasificadoresBronco(*data, *save)
+}
+
+func cmdEnsemble(args []string) error {
+	fs := flag.NewFlagSet("ensemble", flag.ExitOnError)
+	models := fs.String("models", "./weights/softmax_model.json,./weights/tree_model.json,./weights/mlp_model.json", "artefactos separados por coma")
+	pesos := fs.String("weights", "", "pesos separados por coma (vacío = iguales)")
+	minAcuerdo := fs.Float64("min-agreement", 1, "fracción del peso que debe coincidir")
+	out := fs.String("out", "./weights/ensemble_model.json", "ruta del ensamble")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var w []float64
+	if *pesos != "" {
+		var err error
+		if w, err = parseFloats(*pesos); err != nil {
+			return err
+		}
+	}
+	return CrearEnsamble(strings.Split(*models, ","), w, *minAcuerdo, *out)
+}
+
+func cmdGenerate(args []string) error {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	n := fs.Int("n", 800, "filas a generar")
+	seed := fs.Int64("seed", algorithms.DefaultSeed, "semilla")
+	balance := fs.String("balance", "", "pesos por clase de dataset.BroncoClasses separados por coma (vacío = clases balanceadas)")
+	out := fs.String("out", "./algorithms/bronco_synthetic.csv", "CSV de salida")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	cfg := dataset.SyntheticConfig{N: *n, Seed: *seed}
+	if *balance != "" {
+		w, err := parseFloats(*balance)
+		if err != nil {
+			return err
+		}
+		cfg.ClassWeights = w
+	}
+
+	ds, err := dataset.GenerateBronco(cfg)
+	if err != nil {
+		return err
+	}
+	if err := ds.WriteCSV(*out, dataset.BroncoSchema().Label); err != nil {
+		return fmt.Errorf("error al guardar el dataset: %w", err)
+	}
+
+	conteo := make([]int, len(dataset.UrgencyLevels))
+	for _, c := range ds.Y {
+		conteo[c]++
+	}
+	fmt.Printf("Se generaron %d filas en %s\n", len(ds.Y), *out)
+	for k, urgencia := range dataset.UrgencyLevels {
+		fmt.Printf("  urgencia %d (%s): %d\n", k, urgencia, conteo[k])
+	}
+	return nil
+}
+
+func cmdFeedback(args []string) error {
+	fs := flag.NewFlagSet("feedback", flag.ExitOnError)
+	path := fs.String("feedback", server.RutaFeedbackDefault, "archivo JSONL de correcciones de la API")
+	base := fs.String("base", "", "dataset CSV/JSONL cuyas filas van antes de las correcciones (vacío: solo correcciones)")
+	out := fs.String("out", "./weights/bronco_feedback.csv", "CSV de salida con el esquema de bronco_dataset.csv")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+
+	n, omitidas, err := server.ExportarFeedback(*path, *base, *out)
+	if err != nil {
+		return err
+	}
+	if omitidas > 0 {
+		fmt.Printf("%d correcciones omitidas: no tienen las features de bronco_dataset.csv\n", omitidas)
+	}
+	if *base != "" {
+		fmt.Printf("%d correcciones exportadas a %s despues de las filas de %s\n", n, *out, *base)
+	} else {
+		fmt.Printf("%d correcciones exportadas a %s\n", n, *out)
+	}
+	fmt.Printf("Para reentrenar: unmatch train -data %s\n", *out)
+	return nil
+}
+
+func cmdCorpus(args []string) error {
+	fs := flag.NewFlagSet("corpus", flag.ExitOnError)
+	corpus := fs.String("corpus", "./algorithms/corpus_bronco.jsonl", "corpus JSONL de textos etiquetados")
+	modelPath := fs.String("model", algorithms.DefaultSoftmaxModelPath, "artefacto del modelo")
+	scorer := fs.String("scorer", "offline", "puntuador de enfermedades: offline o huggingface")
+	jsonOut := fs.Bool("json", false, "imprimir el reporte completo en JSON")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *scorer != "offline" && *scorer != "huggingface" {
+		return fmt.Errorf("puntuador desconocido '%s'", *scorer)
+	}
+
+	model, err := algorithms.LoadClassifier(*modelPath)
+	if err != nil {
+		return fmt.Errorf("no se pudo cargar el modelo: %w", err)
+	}
+	reporte, err := server.EvaluarCorpus(*corpus, model, *scorer == "offline")
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		salida, err := json.MarshalIndent(reporte, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(salida))
+		return nil
+	}
+
+	fmt.Printf("Corpus %s: %d casos, modelo %s, puntuador %s\n\n", *corpus, reporte.Casos, reporte.Modelo, *scorer)
+	etapas := []struct {
+		nombre string
+		etapa  server.EtapaCorpus
+	}{
+		{"redflag_pecho", reporte.RedflagPecho},
+		{"redflag_respiracion", reporte.RedflagRespiracion},
+		{"enfermedad_scorer", reporte.EnfermedadScorer},
+	}
+	if reporte.EnfermedadModelo != nil {
+		etapas = append(etapas, struct {
+			nombre string
+			etapa  server.EtapaCorpus
+		}{"enfermedad_modelo", *reporte.EnfermedadModelo})
+	}
+	etapas = append(etapas, struct {
+		nombre string
+		etapa  server.EtapaCorpus
+	}{"urgencia_modelo", reporte.UrgenciaModelo})
+	for _, e := range etapas {
+		fmt.Printf("  %-20s %3d/%-3d  %.4f\n", e.nombre, e.etapa.Aciertos, e.etapa.Total, e.etapa.Accuracy)
+	}
+	if len(reporte.Fallos) > 0 {
+		fmt.Println("\nFallos:")
+		for _, f := range reporte.Fallos {
+			fmt.Printf("  línea %d [%s] esperado=%s obtenido=%s: %s\n", f.Linea, f.Etapa, f.Esperado, f.Obtenido, f.Texto)
+		}
+	}
+	return nil
+}
+
+func cmdModels(args []string) error {
+	fs := flag.NewFlagSet("models", flag.ExitOnError)
+	dir := fs.String("registry", algorithms.DefaultRegistryDir, "directorio del registro")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	registro := algorithms.DefaultRegistry()
+	if *dir != algorithms.DefaultRegistryDir {
+		registro = algorithms.NewRegistry(*dir)
+	}
+	if err := registro.LoadKeysFromEnv(); err != nil {
+		return err
+	}
+
+	accion := fs.Arg(0)
+	switch {
+	case accion == "" || accion == "list":
+		versiones, err := registro.List()
+		if err != nil {
+			return err
+		}
+		if len(versiones) == 0 {
+			fmt.Println("El registro está vacío")
+			return nil
+		}
+		for _, v := range versiones {
+			marca := " "
+			if v.Active {
+				marca = "*"
+			}
+			fmt.Printf("%s %s  %-14s accuracy=%.4f  n=%-5d  %s  %s\n", marca, v.ID, v.ModelType,
+				v.Accuracy, v.NSamples, v.CreatedAt.Format(time.RFC3339), v.DatasetHash[:min(12, len(v.DatasetHash))])
+		}
+		return nil
+	case accion == "promote" && fs.NArg() == 2:
+		if err := registro.Sign(fs.Arg(1)); err != nil {
+			return err
+		}
+		if _, err := registro.Load(fs.Arg(1)); err != nil {
+			return err
+		}
+		if err := registro.Promote(fs.Arg(1)); err != nil {
+			return err
+		}
+		fmt.Println("Version activa:", fs.Arg(1))
+		return nil
+	case accion == "rollback" && fs.NArg() == 1:
+		id, err := registro.Rollback()
+		if err != nil {
+			return err
+		}
+		fmt.Println("Version activa:", id)
+		return nil
+	default:
+		return fmt.Errorf("uso: models [-registry dir] list | promote <id> | rollback")
+	}
+}
+
+// ===== firmas =====
+
+func cmdKeygen(args []string) error {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := fs.String("out", "unmatch_ed25519", "ruta de la clave privada; la pública se escribe en <out>.pub")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if err := algorithms.GenerateSigningKey(*out, *out+".pub"); err != nil {
+		return err
+	}
+	fmt.Printf("Clave privada en %s, clave pública en %s.pub\n", *out, *out)
+	fmt.Printf("API: %s=%s %s=%s.pub\n", algorithms.SigningKeyEnv, *out, algorithms.VerifyKeyEnv, *out)
+	return nil
+}
+
+func cmdSign(args []string) error {
+	fs := flag.NewFlagSet("sign", flag.ExitOnError)
+	modelPath := fs.String("model", algorithms.DefaultSoftmaxModelPath, "artefacto a firmar")
+	keyPath := fs.String("key", os.Getenv(algorithms.SigningKeyEnv), "clave privada (por defecto $"+algorithms.SigningKeyEnv+")")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if *keyPath == "" {
+		return fmt.Errorf("-key es requerido")
+	}
+	key, err := algorithms.LoadSigningKey(*keyPath)
+	if err != nil {
+		return err
+	}
+	// solo se firma un artefacto que carga y no fue alterado
+	if _, err := algorithms.LoadClassifier(*modelPath); err != nil {
+		return fmt.Errorf("no se pudo cargar el modelo: %w", err)
+	}
+	if err := algorithms.SignArtifact(*modelPath, key); err != nil {
+		return err
+	}
+	fmt.Println("Firma escrita en", algorithms.SignaturePath(*modelPath))
+	return nil
+}
+
+func cmdVerify(args []string) error {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	modelPath := fs.String("model", algorithms.DefaultSoftmaxModelPath, "artefacto a verificar")
+	keyPath := fs.String("key", os.Getenv(algorithms.VerifyKeyEnv), "clave pública (por defecto $"+algorithms.VerifyKeyEnv+"); sin clave solo se verifica el checksum")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	var key ed25519.PublicKey
+	if *keyPath != "" {
+		var err error
+		if key, err = algorithms.LoadVerifyKey(*keyPath); err != nil {
+			return err
+		}
+	}
+	if _, err := algorithms.LoadVerifiedClassifier(*modelPath, key); err != nil {
+		return err
+	}
+	if key != nil {
+		fmt.Println("Checksum y firma válidos:", *modelPath)
+	} else {
+		fmt.Println("Checksum válido:", *modelPath)
+	}
+	return nil
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// exportLossCSV escribe el historial de pérdida a un CSV.
+// Formato columnas: iter, loss
+func exportLossCSV(path string, loss []float64) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	defer w.Flush()
+
+	if err := w.Write([]string{"iter", "loss"}); err != nil {
+		return err
+	}
+
+	for i, v := range loss {
+		record := []string{
+			strconv.Itoa(i),
+			fmt.Sprintf("%f", v),
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// claseUrgenciaAlta es el índice de la clase "alta" en la columna urgencia.
+const claseUrgenciaAlta = 2
+
+// datasetBroncoPath es el dataset de entrenamiento por defecto.
+const datasetBroncoPath = "./algorithms/bronco_dataset.csv"
+
+// leerDatasetBronco lee un CSV o JSONL con el esquema de
+// bronco_dataset.csv y devuelve la matriz de features X, las etiquetas de
+// 'urgencia' y los nombres de las features en el orden de las columnas de X.
+func leerDatasetBronco(path string) (*mat.Dense, []int, []string, error) {
+	ds, err := dataset.Load(path, dataset.BroncoSchema())
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return ds.X, ds.Y, ds.FeatureNames, nil
+}
+
+// registroDefault es el registro de la API con las claves de
+// UNMATCH_SIGNING_KEY y UNMATCH_VERIFY_KEY.
+func registroDefault() (*algorithms.Registry, error) {
+	registro := algorithms.DefaultRegistry()
+	if err := registro.LoadKeysFromEnv(); err != nil {
+		return nil, err
+	}
+	return registro, nil
+}
+
+// registrarModelo guarda el modelo como una versión nueva del registro sin
+// activarla: el CLI no aplica los gates de promoción, así que activarla queda
+// a cargo de `unmatch models promote`.
+func registrarModelo(model algorithms.Classifier, manifest algorithms.TrainingManifest) error {
+	registro, err := registroDefault()
+	if err != nil {
+		return err
+	}
+	version, err := registro.Register(model, manifest)
+	if err != nil {
+		return fmt.Errorf("error al guardar el modelo: %w", err)
+	}
+	fmt.Printf("Modelo guardado como version %s (sin activar)\n", version.ID)
+	fmt.Printf("Para activarlo: unmatch models promote %s\n", version.ID)
+	return nil
+}
+
+// TuneThresholdsBronco ajusta el umbral de escalamiento de la clase "alta"
+// del modelo activo para alcanzar targetRecall sobre el CSV de validación,
+// y registra el modelo con el umbral como una versión nueva.
+func TuneThresholdsBronco(validationPath string, targetRecall float64) error {
+	registro, err := registroDefault()
+	if err != nil {
+		return err
+	}
+	activo, id, err := registro.LoadActive()
+	if errors.Is(err, algorithms.ErrVersionNotFound) {
+		activo, err = algorithms.LoadVerifiedClassifier(algorithms.DefaultSoftmaxModelPath, registro.VerifyKey)
+	}
+	if err != nil {
+		return fmt.Errorf("no se pudo cargar el modelo Softmax: %w", err)
+	}
+	model, ok := activo.(*algorithms.SoftmaxRegression)
+	if !ok {
+		return fmt.Errorf("el modelo activo (%s) no es softmax", activo.ModelType())
+	}
+
+	// los umbrales escalan a una clase de mayor urgencia: solo tienen
+	// sentido con las clases de la columna urgencia, no con las enfermedades
+	// de dataset.BroncoClasses
+	if _, nClases := model.W.Dims(); nClases != len(dataset.UrgencyLevels) {
+		return fmt.Errorf("el modelo activo tiene %d clases; los umbrales solo aplican a las %d clases de urgencia (%s)",
+			nClases, len(dataset.UrgencyLevels), strings.Join(dataset.UrgencyLevels, ", "))
+	}
+
+	X, y, _, err := leerDatasetBronco(validationPath)
+	if err != nil {
+		return err
+	}
+
+	// con los datos de entrenamiento el recall sale optimista y el umbral
+	// queda demasiado alto
+	var origen algorithms.TrainingManifest
+	if id != "" {
+		if origen, err = registro.Manifest(id); err != nil {
+			return err
+		}
+	} else if bytes, err := os.ReadFile(algorithms.DefaultManifestPath); err == nil {
+		if err := json.Unmarshal(bytes, &origen); err != nil {
+			return fmt.Errorf("%s: %w", algorithms.DefaultManifestPath, err)
+		}
+	}
+	if err := verificarValidacion(validationPath, X, y, origen); err != nil {
+		return err
+	}
+
+	res, err := model.TuneThreshold(X, y, claseUrgenciaAlta, targetRecall)
+	if err != nil {
+		return err
+	}
+	model.SetThreshold(res.Class, res.Threshold)
+
+	fmt.Printf("Umbral clase alta: %.4f (recall %.4f, precision %.4f)\n",
+		res.Threshold, res.Recall, res.Precision)
+
+	// los pesos no cambian: se conserva el manifiesto de entrenamiento de
+	// la versión de origen
+	var manifest algorithms.TrainingManifest
+	if id != "" {
+		manifest = origen
+		manifest.CreatedAt = time.Now().UTC()
+	} else {
+		acc, err := model.Accuracy(X, y)
+		if err != nil {
+			return err
+		}
+		manifest = algorithms.NewTrainingManifest(model, X, y, acc)
+		manifest.DatasetPath = validationPath
+	}
+	return registrarModelo(model, manifest)
+}
+
+// verificarValidacion rechaza un dataset de validación que es el mismo con
+// el que se entrenó el modelo según su manifiesto: el mismo archivo o los
+// mismos datos (hash).
+func verificarValidacion(path string, X *mat.Dense, y []int, entrenamiento algorithms.TrainingManifest) error {
+	if entrenamiento.DatasetHash != "" && entrenamiento.DatasetHash == algorithms.HashDataset(X, y) {
+		return fmt.Errorf("%s tiene los mismos datos con los que se entrenó el modelo activo; use un dataset de validación", path)
+	}
+	if entrenamiento.DatasetPath == "" {
+		return nil
+	}
+	a, errA := os.Stat(path)
+	b, errB := os.Stat(entrenamiento.DatasetPath)
+	if errA == nil && errB == nil && os.SameFile(a, b) {
+		return fmt.Errorf("%s es el dataset de entrenamiento del modelo activo; use un dataset de validación", path)
+	}
+	return nil
+}
+
+// SearchSoftmaxBronco busca hiperparámetros (lr, n_iter, reg_lambda) para un
+// dataset con el esquema bronco con validación cruzada. modo puede ser
+// "grid" o "random" (nTrials combinaciones). Escribe el leaderboard en
+// weights/softmax_search_leaderboard.csv y, si saveBest es true, entrena
+// el mejor modelo con todo el dataset y lo guarda para la API.
+func SearchSoftmaxBronco(datasetPath, modo string, nTrials int, saveBest bool) error {
+	X, y, names, err := leerDatasetBronco(datasetPath)
+	if err != nil {
+		return err
+	}
+
+	cfg := algorithms.SearchConfig{Folds: 3, Workers: runtime.NumCPU(), Seed: algorithms.DefaultSeed}
+
+	var params []algorithms.HyperParams
+	switch modo {
+	case "grid":
+		params = algorithms.GridSpace{
+			Lr:        []float64{0.01, 0.05, 0.1, 0.5},
+			NIter:     []int{500, 1000, 2000, 3000},
+			RegLambda: []float64{1e-4, 1e-3, 1e-2, 1e-1},
+		}.Params()
+	case "random":
+		params, err = algorithms.RandomSpace{
+			LrMin: 1e-3, LrMax: 1,
+			NIterMin: 200, NIterMax: 5000,
+			RegMin: 1e-5, RegMax: 1,
+		}.Sample(nTrials, cfg.Seed)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("modo de búsqueda desconocido '%s' (use grid o random)", modo)
+	}
+
+	fmt.Printf("Búsqueda %s: %d combinaciones, %d folds, %d workers\n", modo, len(params), cfg.Folds, cfg.Workers)
+	results, err := algorithms.Search(X, y, params, cfg)
+	if err != nil {
+		return err
+	}
+
+	_ = os.MkdirAll("./weights", 0o755)
+	if err := algorithms.WriteLeaderboardCSV("./weights/softmax_search_leaderboard.csv", results); err != nil {
+		return fmt.Errorf("error al guardar el leaderboard: %w", err)
+	}
+	fmt.Println("Se generó: weights/softmax_search_leaderboard.csv")
+
+	best := results[0]
+	fmt.Printf("Mejor: lr=%g n_iter=%d reg_lambda=%g accuracy=%.4f (+/- %.4f)\n",
+		best.Params.Lr, best.Params.NIter, best.Params.RegLambda, best.MeanAccuracy, best.StdAccuracy)
+
+	if !saveBest {
+		return nil
+	}
+
+	model := best.Params.NewModel(cfg.Seed)
+	model.FeatureNames = names
+	if err := model.Fit(X, y); err != nil {
+		return fmt.Errorf("error al entrenar el mejor modelo: %w", err)
+	}
+	acc, err := model.Accuracy(X, y)
+	if err != nil {
+		return err
+	}
+	manifest := algorithms.NewTrainingManifest(model, X, y, acc)
+	manifest.DatasetPath = datasetPath
+	return registrarModelo(model, manifest)
+}
+
+// CompararClasificadoresBronco evalúa con validación cruzada cada
+// clasificador de algorithms.ModelTypes sobre un dataset con el esquema
+// bronco y, si saveBest es true, entrena el de mejor accuracy con todo el
+// dataset y lo guarda como modelo de la API (el tipo queda en "model_type").
+func CompararClasificadoresBronco(datasetPath string, saveBest bool) error {
+	X, y, names, err := leerDatasetBronco(datasetPath)
+	if err != nil {
+		return err
+	}
+
+	const folds = 3
+	mejorTipo := ""
+	mejorAcc := -1.0
+	for _, tipo := range algorithms.ModelTypes {
+		nuevo := func() algorithms.Classifier {
+			c, _ := algorithms.NewClassifier(tipo)
+			return c
+		}
+		mean, std, err := algorithms.CrossValidateClassifier(X, y, nuevo, folds, algorithms.DefaultSeed)
+		if err != nil {
+			fmt.Printf("  %-14s error: %v\n", tipo, err)
+			continue
+		}
+		fmt.Printf("  %-14s accuracy=%.4f (+/- %.4f)\n", tipo, mean, std)
+		if mean > mejorAcc {
+			mejorTipo, mejorAcc = tipo, mean
+		}
+	}
+	if mejorTipo == "" {
+		return fmt.Errorf("ningún clasificador pudo evaluarse")
+	}
+	fmt.Printf("Mejor clasificador: %s (%.4f)\n", mejorTipo, mejorAcc)
+
+	if !saveBest {
+		return nil
+	}
+
+	model, err := algorithms.NewClassifier(mejorTipo)
+	if err != nil {
+		return err
+	}
+	if sm, ok := model.(*algorithms.SoftmaxRegression); ok {
+		sm.FeatureNames = names
+	}
+	if err := model.Fit(X, y); err != nil {
+		return fmt.Errorf("error al entrenar %s: %w", mejorTipo, err)
+	}
+	acc, err := algorithms.Accuracy(model, X, y)
+	if err != nil {
+		return err
+	}
+
+	manifest := algorithms.NewTrainingManifest(model, X, y, acc)
+	manifest.DatasetPath = datasetPath
+	return registrarModelo(model, manifest)
+}
+
+// CrearEnsamble combina modelos ya entrenados (uno por archivo de
+// artefacto) en un ensamble de votación suave y lo guarda en salida.
+// pesos puede ser nil para dar el mismo peso a todos; minAcuerdo es la
+// fracción del peso que debe coincidir para no marcar baja confianza.
+func CrearEnsamble(paths []string, pesos []float64, minAcuerdo float64, salida string) error {
+	miembros := make([]algorithms.Classifier, 0, len(paths))
+	for _, p := range paths {
+		m, err := algorithms.LoadClassifier(p)
+		if err != nil {
+			return fmt.Errorf("no se pudo cargar el modelo %s: %w", p, err)
+		}
+		fmt.Printf("  %s: %s\n", p, m.ModelType())
+		miembros = append(miembros, m)
+	}
+
+	ens, err := algorithms.NewEnsemble(miembros, pesos)
+	if err != nil {
+		return err
+	}
+	ens.MinAgreement = minAcuerdo
+
+	if err := ens.SaveToFile(salida); err != nil {
+		return fmt.Errorf("error al guardar el ensamble: %w", err)
+	}
+	fmt.Printf("Ensamble de %d modelos guardado en %s\n", len(miembros), salida)
+	return nil
+}
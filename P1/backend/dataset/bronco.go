@@ -0,0 +1,135 @@
+package dataset
+
+// BroncoSchema is the schema of bronco_dataset.csv, which is also the
+// order of the feature vector built by the API.
+func BroncoSchema() Schema {
+	return Schema{
+		Features: []Column{
+			{"a_asma", TypeFloat},
+			{"a_bronquitis", TypeFloat},
+			{"a_enfisema", TypeFloat},
+			{"a_apnea", TypeFloat},
+			{"a_fibromialgia", TypeFloat},
+			{"a_migranas", TypeFloat},
+			{"a_reflujo", TypeFloat},
+			{"n_sintomas", TypeInt},
+			{"n_cronicas", TypeInt},
+			{"redflag_pecho", TypeBool},
+			{"redflag_respiracion", TypeBool},
+			{"tiene_cronicas", TypeBool},
+		},
+		Label:   "urgencia",
+		Missing: MissingError,
+	}
+}
+
+// Column indices of the bronco feature vector.
+const (
+	colAsma = iota
+	colBronquitis
+	colEnfisema
+	colApnea
+	colFibromialgia
+	colMigranas
+	colReflujo
+	colNSintomas
+	colNCronicas
+	colRedflagPecho
+	colRedflagRespiracion
+	colTieneCronicas
+	nBroncoFeatures
+)
+
+// BroncoClass is what a class index of the bronco models means. The values
+// are the atoms used by the Prolog knowledge base.
+type BroncoClass struct {
+	Urgency string // "baja", "mediana" or "alta"
+	Disease string // "ninguna" or the disease of the a_* score
+	Chronic string // "cronica_si" or "cronica_no"
+}
+
+// BroncoClasses maps the class index predicted by the models to the
+// diagnosis. Class k > 0 is the disease of feature column k-1.
+var BroncoClasses = []BroncoClass{
+	0: {"baja", "ninguna", "cronica_no"},
+	1: {"mediana", "asma", "cronica_si"},
+	2: {"alta", "bronquitis", "cronica_si"},
+	3: {"alta", "enfisema", "cronica_si"},
+	4: {"mediana", "apnea", "cronica_si"},
+	5: {"baja", "fibromialgia", "cronica_si"},
+	6: {"baja", "migranas", "cronica_si"},
+	7: {"baja", "reflujo", "cronica_si"},
+}
+
+// UrgencyLevels are the codes of the urgencia column of bronco_dataset.csv,
+// the label the bronco models are trained on: 0 = baja, 1 = mediana,
+// 2 = alta. They are ordered by urgency.
+var UrgencyLevels = []string{"baja", "mediana", "alta"}
+
+// UrgencyCode returns the urgencia code of an urgency level, or -1 if it is
+// not one of UrgencyLevels.
+func UrgencyCode(urgency string) int {
+	for code, level := range UrgencyLevels {
+		if level == urgency {
+			return code
+		}
+	}
+	return -1
+}
+
+// UrgencyCode returns the urgencia code of the class.
+func (c BroncoClass) UrgencyCode() int {
+	return UrgencyCode(c.Urgency)
+}
+
+// BroncoClassOf returns the diagnosis of a class index.
+func BroncoClassOf(class int) (BroncoClass, bool) {
+	if class < 0 || class >= len(BroncoClasses) {
+		return BroncoClass{}, false
+	}
+	return BroncoClasses[class], true
+}
+
+// DiseaseThreshold is the minimum a_* score for a disease to be diagnosed.
+const DiseaseThreshold = 0.5
+
+// DominantDisease returns the class of the disease with the highest a_*
+// score of x, or 0 when no score reaches DiseaseThreshold (rule 1 of
+// LabelBronco).
+func DominantDisease(x []float64) int {
+	best := colAsma
+	for j := colAsma; j <= colReflujo; j++ {
+		if x[j] > x[best] {
+			best = j
+		}
+	}
+	if x[best] < DiseaseThreshold {
+		return 0
+	}
+	return best + 1
+}
+
+// LabelBronco assigns the class of a bronco feature vector with these rules,
+// applied in order:
+//
+//  1. The disease is the one with the highest a_* score if that score is at
+//     least DiseaseThreshold; otherwise the class is 0 ("ninguna").
+//  2. Classes 1-7 are chronic ("cronica_si"): a disease without chronic
+//     history (tiene_cronicas = 0) and without red flags is class 0.
+//  3. A red flag (redflag_pecho or redflag_respiracion) is never "baja"
+//     urgency with no disease: a class 0 case with a red flag becomes the
+//     "alta" pulmonary class (bronquitis or enfisema) with the higher score.
+func LabelBronco(x []float64) int {
+	class := DominantDisease(x)
+	redFlag := x[colRedflagPecho] == 1 || x[colRedflagRespiracion] == 1
+	if class != 0 && x[colTieneCronicas] == 0 && !redFlag {
+		class = 0
+	}
+	if class == 0 && redFlag {
+		class = colBronquitis + 1
+		if x[colEnfisema] > x[colBronquitis] {
+			class = colEnfisema + 1
+		}
+	}
+	return class
+}
@@ -0,0 +1,372 @@
+// Package dataset loads tabular training data (CSV or JSONL) against a
+// declared Schema and returns it in the shape the algorithms package uses.
+package dataset
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// Dataset is a loaded and validated dataset.
+type Dataset struct {
+	X            *mat.Dense // (n x d) features in schema order
+	Y            []int      // (n,) class indices
+	FeatureNames []string
+	DroppedLines []int // lines skipped by MissingDrop
+	Imputed      int   // number of feature values filled by MissingImpute
+}
+
+// RowError is a validation error in one line of the input file.
+type RowError struct {
+	Line   int    // 1-based line in the file (the CSV header is line 1)
+	Column string // empty when the error concerns the whole row
+	Msg    string
+}
+
+func (e RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("line %d, column %q: %s", e.Line, e.Column, e.Msg)
+}
+
+// ValidationError collects every RowError found while loading a file, so a
+// broken dataset can be fixed in one pass.
+type ValidationError struct {
+	Path   string
+	Errors []RowError
+}
+
+// maxReportedErrors bounds the message of a ValidationError.
+const maxReportedErrors = 20
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d invalid values", e.Path, len(e.Errors))
+	for i, re := range e.Errors {
+		if i == maxReportedErrors {
+			fmt.Fprintf(&b, "\n  ... and %d more", len(e.Errors)-maxReportedErrors)
+			break
+		}
+		b.WriteString("\n  ")
+		b.WriteString(re.Error())
+	}
+	return b.String()
+}
+
+// Load reads a .csv or .jsonl file, choosing the format by extension.
+func Load(path string, schema Schema) (*Dataset, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return LoadCSV(path, schema)
+	case ".jsonl", ".ndjson":
+		return LoadJSONL(path, schema)
+	default:
+		return nil, fmt.Errorf("dataset: unsupported file extension %q", filepath.Ext(path))
+	}
+}
+
+// LoadCSV reads a CSV file with a header row. Columns not declared in the
+// schema are ignored; declared columns may appear in any order.
+func LoadCSV(path string, schema Schema) (*Dataset, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r := csv.NewReader(f)
+	r.FieldsPerRecord = -1 // checked per row to report the line
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: reading header: %w", path, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.TrimSpace(h)] = i
+	}
+	for _, name := range append(schema.FeatureNames(), schema.Label) {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("%s: missing column %q", path, name)
+		}
+	}
+
+	b := newBuilder(path, schema)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// a malformed row (e.g. a bare quote) is a row error; the
+			// reader goes on with the next line
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				b.fail(pe.Line, "", pe.Err.Error())
+				continue
+			}
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		line, _ := r.FieldPos(0)
+		if len(record) != len(header) {
+			b.fail(line, "", fmt.Sprintf("expected %d fields, got %d", len(header), len(record)))
+			continue
+		}
+		b.addRow(line, func(name string) (string, bool) {
+			v := strings.TrimSpace(record[index[name]])
+			return v, !isMissing(v)
+		})
+	}
+	return b.build()
+}
+
+// LoadJSONL reads a file with one JSON object per line. Absent keys and
+// null values are missing values.
+func LoadJSONL(path string, schema Schema) (*Dataset, error) {
+	if err := schema.Validate(); err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := newBuilder(path, schema)
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" {
+			continue
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal([]byte(text), &obj); err != nil {
+			b.fail(line, "", "invalid JSON: "+err.Error())
+			continue
+		}
+		b.addRow(line, func(name string) (string, bool) {
+			raw, ok := obj[name]
+			if !ok || string(raw) == "null" {
+				return "", false
+			}
+			var s string
+			if json.Unmarshal(raw, &s) == nil {
+				s = strings.TrimSpace(s)
+				return s, !isMissing(s)
+			}
+			return string(raw), true
+		})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return b.build()
+}
+
+// isMissing reports whether a raw cell means "no value".
+func isMissing(v string) bool {
+	switch strings.ToLower(v) {
+	case "", "na", "nan", "null":
+		return true
+	}
+	return false
+}
+
+// builder accumulates validated rows; missing feature values are stored as
+// NaN until build imputes them.
+type builder struct {
+	path   string
+	schema Schema
+	data   []float64
+	y      []int
+	errs   []RowError
+	drops  []int
+}
+
+func newBuilder(path string, schema Schema) *builder {
+	return &builder{path: path, schema: schema}
+}
+
+func (b *builder) fail(line int, column, msg string) {
+	b.errs = append(b.errs, RowError{Line: line, Column: column, Msg: msg})
+}
+
+// addRow parses one row; get returns the raw value of a column and whether
+// it is present.
+func (b *builder) addRow(line int, get func(name string) (string, bool)) {
+	policy := b.schema.Missing
+	row := make([]float64, len(b.schema.Features))
+	ok := true
+	missing := false
+
+	raw, present := get(b.schema.Label)
+	label := 0
+	if !present {
+		missing = true
+		if policy != MissingDrop {
+			b.fail(line, b.schema.Label, "missing label")
+			ok = false
+		}
+	} else if l, err := b.schema.parseLabel(raw); err != nil {
+		b.fail(line, b.schema.Label, err.Error())
+		ok = false
+	} else {
+		label = l
+	}
+
+	for j, c := range b.schema.Features {
+		raw, present := get(c.Name)
+		if !present {
+			missing = true
+			row[j] = math.NaN()
+			if policy == "" || policy == MissingError {
+				b.fail(line, c.Name, "missing value")
+				ok = false
+			}
+			continue
+		}
+		v, err := parseValue(c.Type, raw)
+		if err != nil {
+			b.fail(line, c.Name, err.Error())
+			ok = false
+			continue
+		}
+		row[j] = v
+	}
+
+	if !ok {
+		return
+	}
+	if missing && policy == MissingDrop {
+		b.drops = append(b.drops, line)
+		return
+	}
+	b.data = append(b.data, row...)
+	b.y = append(b.y, label)
+}
+
+// build imputes missing values and returns the dataset, or the collected
+// validation errors.
+func (b *builder) build() (*Dataset, error) {
+	if len(b.errs) > 0 {
+		return nil, &ValidationError{Path: b.path, Errors: b.errs}
+	}
+	if len(b.y) == 0 {
+		return nil, fmt.Errorf("%s: no valid rows", b.path)
+	}
+	d := &Dataset{
+		FeatureNames: b.schema.FeatureNames(),
+		DroppedLines: b.drops,
+	}
+	nFeatures := len(b.schema.Features)
+	for j, c := range b.schema.Features {
+		fill, found := imputeValue(c.Type, b.data, j, nFeatures)
+		for i := j; i < len(b.data); i += nFeatures {
+			if math.IsNaN(b.data[i]) {
+				if !found {
+					return nil, fmt.Errorf("%s: column %q has no values to impute from", b.path, c.Name)
+				}
+				b.data[i] = fill
+				d.Imputed++
+			}
+		}
+	}
+	d.X = mat.NewDense(len(b.y), nFeatures, b.data)
+	d.Y = b.y
+	return d, nil
+}
+
+// imputeValue returns the mean of a float column, the rounded mean of an
+// int column or the mode of a bool column, ignoring missing values.
+func imputeValue(t ColumnType, data []float64, j, stride int) (float64, bool) {
+	sum, n, ones := 0.0, 0, 0
+	for i := j; i < len(data); i += stride {
+		if math.IsNaN(data[i]) {
+			continue
+		}
+		sum += data[i]
+		n++
+		if data[i] == 1 {
+			ones++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	switch t {
+	case TypeInt:
+		return math.Round(sum / float64(n)), true
+	case TypeBool:
+		if 2*ones >= n {
+			return 1, true
+		}
+		return 0, true
+	default:
+		return sum / float64(n), true
+	}
+}
+
+// parseValue converts a raw cell to float64 according to its type.
+func parseValue(t ColumnType, raw string) (float64, error) {
+	switch t {
+	case TypeInt:
+		v, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			// accept "3.0" from tools that write every number as float
+			f, ferr := strconv.ParseFloat(raw, 64)
+			if ferr != nil || f != math.Trunc(f) {
+				return 0, fmt.Errorf("%q is not an integer", raw)
+			}
+			return f, nil
+		}
+		return float64(v), nil
+	case TypeBool:
+		switch strings.ToLower(raw) {
+		case "1", "1.0", "true":
+			return 1, nil
+		case "0", "0.0", "false":
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%q is not a boolean", raw)
+	default:
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return 0, fmt.Errorf("%q is not a finite number", raw)
+		}
+		return v, nil
+	}
+}
+
+// parseLabel converts a raw label to a class index using LabelMap, or as a
+// non-negative integer when there is no map.
+func (s Schema) parseLabel(raw string) (int, error) {
+	if len(s.LabelMap) > 0 {
+		class, ok := s.LabelMap[raw]
+		if !ok {
+			return 0, fmt.Errorf("unknown label %q", raw)
+		}
+		return class, nil
+	}
+	class, err := strconv.Atoi(raw)
+	if err != nil || class < 0 {
+		return 0, fmt.Errorf("label %q is not a non-negative integer", raw)
+	}
+	return class, nil
+}
@@ -0,0 +1,128 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ColumnType is the declared type of a feature column.
+type ColumnType string
+
+const (
+	TypeFloat ColumnType = "float"
+	TypeInt   ColumnType = "int"
+	TypeBool  ColumnType = "bool" // 0/1 or true/false, loaded as 0.0/1.0
+)
+
+// MissingPolicy says what to do with rows that have missing values.
+type MissingPolicy string
+
+const (
+	MissingError  MissingPolicy = "error"  // report the row as invalid
+	MissingDrop   MissingPolicy = "drop"   // skip the row
+	MissingImpute MissingPolicy = "impute" // fill features (mean / mode)
+)
+
+// Column declares one feature column.
+type Column struct {
+	Name string     `json:"name"`
+	Type ColumnType `json:"type"`
+}
+
+// Schema declares which columns are features, their types, the label
+// column and how to turn label values into class indices.
+type Schema struct {
+	Features []Column `json:"features"`
+	Label    string   `json:"label"`
+	// LabelMap maps raw label values to class indices. When empty the label
+	// must already be a non-negative integer.
+	LabelMap map[string]int `json:"label_map,omitempty"`
+	Missing  MissingPolicy  `json:"missing,omitempty"`
+}
+
+// FeatureNames returns the names of the feature columns in order.
+func (s Schema) FeatureNames() []string {
+	names := make([]string, len(s.Features))
+	for i, c := range s.Features {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// Validate checks that the schema itself is usable.
+func (s Schema) Validate() error {
+	if len(s.Features) == 0 {
+		return fmt.Errorf("schema: no feature columns")
+	}
+	if s.Label == "" {
+		return fmt.Errorf("schema: label column is required")
+	}
+	seen := map[string]bool{s.Label: true}
+	for _, c := range s.Features {
+		if seen[c.Name] {
+			return fmt.Errorf("schema: duplicated column %q", c.Name)
+		}
+		seen[c.Name] = true
+		switch c.Type {
+		case TypeFloat, TypeInt, TypeBool:
+		default:
+			return fmt.Errorf("schema: column %q has unknown type %q", c.Name, c.Type)
+		}
+	}
+	for raw, class := range s.LabelMap {
+		if class < 0 {
+			return fmt.Errorf("schema: label %q maps to negative class %d", raw, class)
+		}
+	}
+	switch s.Missing {
+	case "", MissingError, MissingDrop, MissingImpute:
+	default:
+		return fmt.Errorf("schema: unknown missing policy %q", s.Missing)
+	}
+	return nil
+}
+
+// LoadSchema reads a schema from a JSON file.
+func LoadSchema(path string) (Schema, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return Schema{}, err
+	}
+	var s Schema
+	if err := json.Unmarshal(bytes, &s); err != nil {
+		return Schema{}, fmt.Errorf("schema %s: %w", path, err)
+	}
+	return s, s.Validate()
+}
+
+// InferCSVSchema builds a schema from the header of a CSV file: label is the
+// label column and every other column is a float feature.
+func InferCSVSchema(path, label string) (Schema, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Schema{}, err
+	}
+	defer f.Close()
+
+	header, err := csv.NewReader(f).Read()
+	if err != nil {
+		return Schema{}, fmt.Errorf("%s: reading header: %w", path, err)
+	}
+	s := Schema{Label: label, Missing: MissingError}
+	found := false
+	for _, h := range header {
+		h = strings.TrimSpace(h)
+		if h == label {
+			found = true
+			continue
+		}
+		s.Features = append(s.Features, Column{Name: h, Type: TypeFloat})
+	}
+	if !found {
+		return Schema{}, fmt.Errorf("%s: missing column %q", path, label)
+	}
+	return s, s.Validate()
+}
@@ -0,0 +1,190 @@
+package dataset
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+
+	"gonum.org/v1/gonum/mat"
+)
+
+// maxSintomas is the number of symptom keywords the API counts.
+const maxSintomas = 14
+
+// SyntheticConfig controls GenerateBronco.
+type SyntheticConfig struct {
+	N    int   // number of rows
+	Seed int64 // same seed, same rows
+	// ClassWeights is the relative share of every class in BroncoClasses
+	// (diseases, not urgency codes). Nil means balanced classes.
+	ClassWeights []float64
+}
+
+// GenerateBronco samples n bronco feature vectors and labels them with the
+// urgency code (see UrgencyLevels) of the class LabelBronco assigns, the
+// same label space as the urgencia column of bronco_dataset.csv. Rows are
+// drawn around a target class (high score for its disease, symptoms and red
+// flags that match its urgency) and kept only when the rules agree with the
+// target, so the class shares follow ClassWeights while every label comes
+// from the documented rules.
+func GenerateBronco(cfg SyntheticConfig) (*Dataset, error) {
+	nClasses := len(BroncoClasses)
+	if cfg.N <= 0 {
+		return nil, fmt.Errorf("GenerateBronco: n must be positive, got %d", cfg.N)
+	}
+	weights := cfg.ClassWeights
+	if weights == nil {
+		weights = make([]float64, nClasses)
+		for i := range weights {
+			weights[i] = 1
+		}
+	}
+	if len(weights) != nClasses {
+		return nil, fmt.Errorf("GenerateBronco: %d class weights for %d classes", len(weights), nClasses)
+	}
+	quotas, err := classQuotas(cfg.N, weights)
+	if err != nil {
+		return nil, err
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+	data := make([]float64, 0, cfg.N*nBroncoFeatures)
+	y := make([]int, 0, cfg.N)
+	for class, quota := range quotas {
+		// the proposal matches the rules most of the time; the bound only
+		// guards against weights that the rules can never satisfy
+		for attempts := 0; quota > 0; attempts++ {
+			if attempts > 1000*cfg.N {
+				return nil, fmt.Errorf("GenerateBronco: could not sample class %d", class)
+			}
+			x := sampleBronco(rng, class)
+			if LabelBronco(x) != class {
+				continue
+			}
+			data = append(data, x...)
+			y = append(y, BroncoClasses[class].UrgencyCode())
+			quota--
+		}
+	}
+
+	// shuffle so the classes are not in blocks
+	n := len(y)
+	X := mat.NewDense(n, nBroncoFeatures, data)
+	perm := rng.Perm(n)
+	shuffled := mat.NewDense(n, nBroncoFeatures, nil)
+	ys := make([]int, n)
+	for i, p := range perm {
+		shuffled.SetRow(i, X.RawRowView(p))
+		ys[i] = y[p]
+	}
+	return &Dataset{X: shuffled, Y: ys, FeatureNames: BroncoSchema().FeatureNames()}, nil
+}
+
+// classQuotas splits n rows among classes proportionally to weights.
+func classQuotas(n int, weights []float64) ([]int, error) {
+	total := 0.0
+	for _, w := range weights {
+		if w < 0 || math.IsNaN(w) {
+			return nil, fmt.Errorf("GenerateBronco: class weights must be non-negative")
+		}
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("GenerateBronco: class weights sum to zero")
+	}
+	quotas := make([]int, len(weights))
+	assigned := 0
+	for i, w := range weights {
+		quotas[i] = int(float64(n) * w / total)
+		assigned += quotas[i]
+	}
+	// rows lost to rounding go to the classes with weight, in order
+	for i := 0; assigned < n; i = (i + 1) % len(weights) {
+		if weights[i] > 0 {
+			quotas[i]++
+			assigned++
+		}
+	}
+	return quotas, nil
+}
+
+// sampleBronco draws one feature vector around the given class.
+func sampleBronco(rng *rand.Rand, class int) []float64 {
+	c := BroncoClasses[class]
+	x := make([]float64, nBroncoFeatures)
+
+	// disease scores: background noise, the class disease clearly above
+	// DiseaseThreshold
+	for j := colAsma; j <= colReflujo; j++ {
+		x[j] = round2(rng.Float64() * 0.45)
+	}
+	if class > 0 {
+		x[class-1] = round2(0.55 + rng.Float64()*0.45)
+	}
+
+	// chronic history: required by classes 1-7 unless a red flag is present
+	pChronic := 0.3
+	if c.Chronic == "cronica_si" {
+		pChronic = 0.9
+	}
+	if rng.Float64() < pChronic {
+		x[colNCronicas] = float64(1 + rng.Intn(5))
+		x[colTieneCronicas] = 1
+	}
+
+	// red flags and symptom count grow with the urgency
+	var pPecho, pResp float64
+	var minSint, maxSint int
+	switch c.Urgency {
+	case "alta":
+		pPecho, pResp, minSint, maxSint = 0.6, 0.7, 4, 10
+	case "mediana":
+		pPecho, pResp, minSint, maxSint = 0.25, 0.3, 2, 7
+	default:
+		pPecho, pResp, minSint, maxSint = 0.1, 0.05, 0, 5
+	}
+	if rng.Float64() < pPecho {
+		x[colRedflagPecho] = 1
+	}
+	if rng.Float64() < pResp {
+		x[colRedflagRespiracion] = 1
+	}
+	x[colNSintomas] = float64(min(maxSintomas, minSint+rng.Intn(maxSint-minSint+1)))
+	return x
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// WriteCSV writes the dataset with a header of FeatureNames plus label, in
+// the format LoadCSV reads.
+func (d *Dataset) WriteCSV(path, label string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	if err := w.Write(append(append([]string(nil), d.FeatureNames...), label)); err != nil {
+		return err
+	}
+	r, _ := d.X.Dims()
+	for i := 0; i < r; i++ {
+		row := d.X.RawRowView(i)
+		record := make([]string, 0, len(row)+1)
+		for _, v := range row {
+			record = append(record, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		record = append(record, strconv.Itoa(d.Y[i]))
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
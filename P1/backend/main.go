@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"unmatch/backend/server"
+)
+
+// main levanta la API en el puerto 8080. Para entrenar o evaluar modelos
+// desde la terminal use el CLI de cmd/unmatch.
+func main() {
+	if err := server.Run(":8080"); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+)
+
+// ActualizacionRequest es el cuerpo de POST /modelos/actualizar. Sin x/y se
+// usan las correcciones de los clínicos (UNMATCH_FEEDBACK). La validación es
+// siempre la del servidor (validation_path de los gates). pasos, lr y
+// max_paso acotan la actualización (ver algorithms.PartialFitConfig); 0
+// toma el valor por defecto.
+type ActualizacionRequest struct {
+	X       [][]float64 `json:"x"`
+	Y       []int       `json:"y"`
+	Pasos   int         `json:"pasos"`
+	Lr      float64     `json:"lr"`
+	MaxPaso float64     `json:"max_paso"`
+}
+
+// errModeloCambiado indica que el modelo servido cambió (promoción,
+// rollback, recarga) mientras se calculaba la actualización.
+var errModeloCambiado = errors.New("el modelo servido cambio durante la actualizacion")
+
+// muActualizacion serializa las actualizaciones: dos a la vez partirían del
+// mismo modelo y la segunda descartaría la primera.
+var muActualizacion sync.Mutex
+
+// lotePorDefecto arma el lote de actualización con las correcciones de los
+// clínicos, con la urgencia de cada clase corregida como etiqueta (el
+// espacio de etiquetas del modelo).
+func lotePorDefecto() (*mat.Dense, []int, error) {
+	muFeedback.Lock()
+	registros, err := LeerFeedback(rutaFeedback())
+	muFeedback.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+	var X [][]float64
+	var y []int
+	for _, r := range registros {
+		etiqueta, err := r.etiquetaModelo()
+		if err != nil {
+			return nil, nil, err
+		}
+		X = append(X, r.Features)
+		y = append(y, etiqueta)
+	}
+	if len(X) == 0 {
+		return nil, nil, nil
+	}
+	Xmat, err := slice2DToDense(X)
+	return Xmat, y, err
+}
+
+// actualizarModelo continúa el entrenamiento del modelo softmax servido con
+// un lote nuevo (PartialFit sobre una copia) y registra el resultado como
+// una versión nueva. Solo se activa con auto_promote y si, sobre el
+// conjunto de validación del servidor, no empeora frente al modelo servido
+// más de lo que permiten los gates. Sin conjunto de validación, o si es el
+// de entrenamiento del modelo servido, no se actualiza.
+func actualizarModelo(c *fiber.Ctx) error {
+	var req ActualizacionRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Error al parsear JSON de entrada", "detalle": err.Error()})
+	}
+
+	muActualizacion.Lock()
+	defer muActualizacion.Unlock()
+
+	servido, err := modeloActivo.obtenerOCargar()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Modelo no entrenado. Primero llame a /softmax/train"})
+	}
+	base, ok := servido.Modelo.(*algorithms.SoftmaxRegression)
+	if !ok {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("Solo se puede actualizar un modelo softmax; el modelo servido es %s", servido.Modelo.ModelType()),
+		})
+	}
+
+	// lote de actualización
+	var X *mat.Dense
+	y := req.Y
+	origenLote := "peticion"
+	if len(req.X) > 0 {
+		if len(req.X) != len(req.Y) {
+			return c.Status(400).JSON(fiber.Map{"error": "X e Y deben tener el mismo tamaño"})
+		}
+		if X, err = slice2DToDense(req.X); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+	} else {
+		origenLote = "feedback"
+		if X, y, err = lotePorDefecto(); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		if X == nil {
+			return c.Status(400).JSON(fiber.Map{"error": "No hay correcciones de clinicos para actualizar el modelo"})
+		}
+	}
+
+	// conjunto de validación del servidor, distinto del de entrenamiento
+	gates, err := algorithms.LoadPromotionGates(algorithms.DefaultGatesPath)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	XVal, yVal, err := conjuntoValidacion(gates)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if XVal == nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "No hay conjunto de validacion: configure validation_path en " + algorithms.DefaultGatesPath,
+		})
+	}
+	if servido.Version != "" {
+		manifest, err := registroModelos.Manifest(servido.Version)
+		if err == nil && manifest.DatasetHash == algorithms.HashDataset(XVal, yVal) {
+			return c.Status(409).JSON(fiber.Map{
+				"error": "El conjunto de validacion es el de entrenamiento del modelo servido; use uno separado",
+			})
+		}
+	}
+
+	cfg := algorithms.DefaultPartialFitConfig()
+	if req.Pasos > 0 {
+		cfg.Steps = req.Pasos
+	}
+	if req.Lr > 0 {
+		cfg.Lr = req.Lr
+	}
+	if req.MaxPaso > 0 {
+		cfg.MaxStepNorm = req.MaxPaso
+	}
+
+	candidato := base.Clone()
+	reporte, err := candidato.PartialFit(X, y, cfg)
+	if err != nil {
+		return c.Status(statusModelo(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	evaluacion, err := algorithms.EvaluateCandidate(candidato, base, XVal, yVal, gates)
+	if err != nil {
+		return c.Status(statusModelo(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	acc, err := candidato.Accuracy(X, y)
+	if err != nil {
+		return c.Status(statusModelo(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	manifest := algorithms.NewTrainingManifest(candidato, X, y, acc)
+	manifest.BaseVersion = servido.Version
+	version, err := registroModelos.Register(candidato, manifest)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Error al guardar modelo", "detalle": err.Error()})
+	}
+	if err := registroModelos.SaveEvaluation(version.ID, evaluacion); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Error al guardar evaluacion", "detalle": err.Error()})
+	}
+
+	promovido := evaluacion.Passed && gates.AutoPromote
+	mensaje := "Actualizacion registrada pero no activada: no pasa los gates sobre el conjunto de validacion"
+	if evaluacion.Passed && !gates.AutoPromote {
+		mensaje = "Actualizacion registrada; pasa los gates pero requiere aprobacion de un admin"
+	}
+	if promovido {
+		err := modeloActivo.activar(candidato, version.ID, "actualizacion", func() error {
+			if modeloActivo.obtener() != servido {
+				return errModeloCambiado
+			}
+			if err := registroModelos.Sign(version.ID); err != nil {
+				return err
+			}
+			return registroModelos.Promote(version.ID)
+		})
+		if errors.Is(err, errModeloCambiado) {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error(), "version": version.ID})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Error al activar modelo", "detalle": err.Error()})
+		}
+		mensaje = "Modelo actualizado y activado"
+	}
+	fmt.Printf("Actualizacion de %s con %d muestras (%s): version %s (promovido: %v)\n",
+		servido.Version, reporte.NSamples, origenLote, version.ID, promovido)
+
+	return c.JSON(fiber.Map{
+		"mensaje":       mensaje,
+		"base":          servido.Version,
+		"version":       version.ID,
+		"promovido":     promovido,
+		"lote":          origenLote,
+		"actualizacion": reporte,
+		"configuracion": cfg,
+		"evaluacion":    evaluacion,
+		"manifest":      manifest,
+	})
+}
@@ -0,0 +1,1346 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+	"github.com/joho/godotenv"
+	"github.com/mndrix/golog"
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// ============================================================================
+// ESTRUCTURAS DE DATOS
+// ============================================================================
+
+// MedicamentoEvaluado representa un medicamento con su evaluación de contraindicación
+
+type MedicamentoRecomendado struct {
+	Urgencia    string
+	Enfermedad  string
+	Cronica     string
+	Pecho       string
+	Respiracion string
+	Medicamento string
+	Match       float64
+}
+
+// DiagnosticoRequest es la solicitud del cliente con el texto médico
+type DiagnosticoRequest struct {
+	Texto string `json:"texto"`
+}
+
+// DiagnosticoResponse es la respuesta con medicamentos evaluados
+type DiagnosticoResponse struct {
+	ID                        string                   `json:"id"`
+	EnfermedadDetectada       string                   `json:"enfermedad_detectada"`
+	NivelUrgencia             string                   `json:"nivel_urgencia"`
+	ProbabilidadesHuggingFace map[string]float64       `json:"probabilidades_huggingface"`
+	ClaseSoftmax              int                      `json:"clase_softmax"`
+	Explicacion               *algorithms.Explanation  `json:"explicacion,omitempty"`
+	VotacionModelos           *algorithms.EnsembleVote `json:"votacion_modelos,omitempty"`
+	Incertidumbre             algorithms.Uncertainty   `json:"incertidumbre"`
+	EnRevision                bool                     `json:"en_revision"` // quedó en la cola de revisión clínica
+	MedicamentosEvaluados     []MedicamentoRecomendado `json:"medicamentos_evaluados"`
+	TotalContraindicados      int                      `json:"total_contraindicados"`
+	Advertencias              []string                 `json:"advertencias"`
+	TextoRecibido             string                   `json:"texto_recibido"`
+}
+
+// VectorEntrada contiene todas las características extraídas para el modelo
+type VectorEntrada struct {
+	a_asma         float32
+	a_bronquitis   float32
+	a_enfisema     float32
+	a_apnea        float32
+	a_fibromialgia float32
+	a_migranas     float32
+	a_reflujo      float32
+
+	n_sintomas          int
+	n_cronicas          int
+	redflag_pecho       bool
+	redflag_respiracion bool
+	tiene_cronicas      bool
+}
+
+// FeaturesTexto contiene las características extraídas del análisis de texto
+type FeaturesTexto struct {
+	n_sintomas          int
+	n_cronicas          int
+	redflag_pecho       bool
+	redflag_respiracion bool
+	tiene_cronicas      bool
+}
+
+// ============================================================================
+// MAPEOS
+// ============================================================================
+
+// El significado de cada clase del modelo (urgencia, enfermedad, crónica)
+// está en dataset.BroncoClasses, compartido con el generador de datos.
+
+// nombresFeatures son los nombres de las columnas del vector de entrada del
+// modelo Softmax, en el mismo orden que bronco_dataset.csv.
+var nombresFeatures = dataset.BroncoSchema().FeatureNames()
+
+var sintomasKeywords = []string{
+	"pecho", "tos", "flema", "silbido", "falta de aire",
+	"ahogo", "dificultad para respirar", "opresion", "dolor al respirar",
+	"cansancio", "fatiga", "sibilancias", "esputo", "mucosidad",
+}
+
+var cronicasKeywords = []string{
+	"asma", "epoc", "bronquitis cronica", "fibrosis pulmonar",
+	"enfisema", "apnea", "cronico", "cronica", "años de", "desde hace",
+}
+
+var pechoKeywords = []string{
+	"dolor de pecho", "opresion en el pecho", "presion en el pecho",
+	"pecho apretado", "dolor toracico", "dolor intenso en el pecho",
+}
+
+var respiracionKeywords = []string{
+	"no puedo respirar", "falta de aire severa", "ahogo",
+	"dificultad extrema", "labios azules", "cianosis", "me ahogo",
+}
+
+// ============================================================================
+// VARIABLES GLOBALES
+// ============================================================================
+
+var maquinaProlog golog.Machine
+
+const softmaxModelPath = algorithms.DefaultSoftmaxModelPath
+
+// registroModelos guarda cada modelo entrenado como una versión; la activa
+// se copia también a softmaxModelPath.
+var registroModelos = algorithms.DefaultRegistry()
+
+// cargarModeloActivo carga la versión activa del registro o, si todavía no
+// hay registro, el artefacto de softmaxModelPath. Con UNMATCH_VERIFY_KEY se
+// rechazan los artefactos sin firma válida.
+func cargarModeloActivo() (algorithms.Classifier, string, error) {
+	model, id, err := registroModelos.LoadActive()
+	if errors.Is(err, algorithms.ErrVersionNotFound) {
+		model, err = algorithms.LoadVerifiedClassifier(softmaxModelPath, registroModelos.VerifyKey)
+		return model, "", err
+	}
+	return model, id, err
+}
+
+// ============================================================================
+// FUNCIONES DE UTILIDAD
+// ============================================================================
+
+func cargarProlog(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		panic(fmt.Sprintf("Error al cargar Prolog: %v", err))
+	}
+	return string(data)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1.0
+	}
+	return 0.0
+}
+
+func slice2DToDense(x [][]float64) (*mat.Dense, error) {
+	if len(x) == 0 {
+		return nil, fmt.Errorf("la matriz X no puede estar vacía")
+	}
+	nSamples := len(x)
+	nFeatures := len(x[0])
+	data := make([]float64, 0, nSamples*nFeatures)
+
+	for i := 0; i < nSamples; i++ {
+		if len(x[i]) != nFeatures {
+			return nil, fmt.Errorf("todas las filas de X deben tener el mismo número de columnas")
+		}
+		data = append(data, x[i]...)
+	}
+	return mat.NewDense(nSamples, nFeatures, data), nil
+}
+
+// statusModelo traduce un error del paquete algorithms a un código HTTP:
+// 400 si el problema está en los datos enviados, 500 en otro caso.
+func statusModelo(err error) int {
+	if algorithms.IsInputError(err) {
+		return fiber.StatusBadRequest
+	}
+	return fiber.StatusInternalServerError
+}
+
+// conjuntoValidacion carga el conjunto de validación de los gates
+// (validation_path), con el esquema de bronco_dataset.csv. Sin ruta
+// configurada devuelve nil y ningún candidato pasa los gates.
+func conjuntoValidacion(gates algorithms.PromotionGates) (*mat.Dense, []int, error) {
+	if gates.ValidationPath == "" {
+		return nil, nil, nil
+	}
+	ds, err := dataset.Load(gates.ValidationPath, dataset.BroncoSchema())
+	if err != nil {
+		return nil, nil, fmt.Errorf("conjunto de validacion: %w", err)
+	}
+	return ds.X, ds.Y, nil
+}
+
+// errorIntegridad indica si err es un artefacto alterado o sin la firma
+// requerida.
+func errorIntegridad(err error) bool {
+	return errors.Is(err, algorithms.ErrCorruptArtifact) ||
+		errors.Is(err, algorithms.ErrUnsigned) ||
+		errors.Is(err, algorithms.ErrBadSignature)
+}
+
+// soloAdmin protege las rutas que cambian el modelo activo. El token se
+// configura con UNMATCH_ADMIN_TOKEN y se envía como "Authorization: Bearer
+// <token>" o en X-Admin-Token; sin token configurado las rutas quedan
+// cerradas.
+func soloAdmin(c *fiber.Ctx) error {
+	esperado := os.Getenv("UNMATCH_ADMIN_TOKEN")
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" {
+		token = c.Get("X-Admin-Token")
+	}
+	if esperado == "" || subtle.ConstantTimeCompare([]byte(token), []byte(esperado)) != 1 {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "se requiere token de admin"})
+	}
+	return c.Next()
+}
+
+func denseTo2D(m *mat.Dense) [][]float64 {
+	r, c := m.Dims()
+	out := make([][]float64, r)
+	for i := 0; i < r; i++ {
+		row := m.RawRowView(i)
+		dst := make([]float64, c)
+		copy(dst, row)
+		out[i] = dst
+	}
+	return out
+}
+
+// ============================================================================
+// ANÁLISIS DE TEXTO
+// ============================================================================
+
+func analizarTexto(texto string) FeaturesTexto {
+	textoLower := strings.ToLower(texto)
+
+	var features FeaturesTexto
+
+	for _, sintoma := range sintomasKeywords {
+		if strings.Contains(textoLower, sintoma) {
+			features.n_sintomas++
+		}
+	}
+
+	for _, cronica := range cronicasKeywords {
+		if strings.Contains(textoLower, cronica) {
+			features.n_cronicas++
+			features.tiene_cronicas = true
+		}
+	}
+
+	for _, keyword := range pechoKeywords {
+		if strings.Contains(textoLower, keyword) {
+			features.redflag_pecho = true
+			break
+		}
+	}
+
+	for _, keyword := range respiracionKeywords {
+		if strings.Contains(textoLower, keyword) {
+			features.redflag_respiracion = true
+			break
+		}
+	}
+
+	return features
+}
+
+// ============================================================================
+// INTEGRACIÓN CON HUGGINGFACE
+// ============================================================================
+
+func llamarHuggingFace(texto string) (map[string]float64, error) {
+	fmt.Println("Llamando a HuggingFace API...")
+
+	token := os.Getenv("HF_TOKEN")
+	if token == "" {
+		return nil, fmt.Errorf("la variable de entorno HF_TOKEN no está configurada")
+	}
+
+	url := "https://router.huggingface.co/hf-inference/models/PlanTL-GOB-ES/bsc-bio-ehr-es"
+
+	textoConMask := texto + " padezco de <mask>."
+
+	payload, err := json.Marshal(map[string]string{
+		"inputs": textoConMask,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error al crear payload: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("error al crear request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error al hacer request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error al leer respuesta: %v", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("error de HuggingFace: %s - %s", resp.Status, string(body))
+	}
+
+	var resultado interface{}
+	if err := json.Unmarshal(body, &resultado); err != nil {
+		return nil, fmt.Errorf("error al parsear JSON: %v", err)
+	}
+
+	probabilidades := make(map[string]float64)
+
+	if resultArray, ok := resultado.([]interface{}); ok && len(resultArray) > 0 {
+		for _, item := range resultArray {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				if token, ok := itemMap["token_str"].(string); ok {
+					if score, ok := itemMap["score"].(float64); ok {
+						probabilidades[token] = score
+					}
+				}
+			}
+		}
+	}
+
+	fmt.Printf("HuggingFace: %d enfermedades detectadas\n", len(probabilidades))
+	return probabilidades, nil
+}
+
+// ============================================================================
+// EVALUACIÓN DE MEDICAMENTOS CON PROLOG
+// ============================================================================
+
+func obtenerMedicamentosContraindicados(
+	m golog.Machine,
+	urgencia, enfermedad, cronica, pecho, respiracion string,
+) []MedicamentoRecomendado {
+
+	query := "medicamento_contraindicado(Urg, Enf, Cron, Pecho, Resp, Med)."
+	solutions := m.ProveAll(query)
+
+	results := []MedicamentoRecomendado{}
+
+	for _, sol := range solutions {
+		urg := sol.ByName_("Urg").String()
+		enf := sol.ByName_("Enf").String()
+		cron := sol.ByName_("Cron").String()
+		pech := sol.ByName_("Pecho").String()
+		resp := sol.ByName_("Resp").String()
+		med := sol.ByName_("Med").String()
+
+		matchCount := 0
+
+		if urg == urgencia {
+			matchCount++
+		}
+		if enf == enfermedad {
+			matchCount++
+		}
+		if cron == cronica {
+			matchCount++
+		}
+		if pech == pecho {
+			matchCount++
+		}
+		if resp == respiracion {
+			matchCount++
+		}
+
+		if urgencia == "alta" && respiracion == "resp_si" {
+			matchCount++
+		}
+
+		if (enfermedad == "asma" || enfermedad == "bronquitis" || enfermedad == "enfisema") &&
+			cronica == "cronica_si" && respiracion == "resp_si" {
+			matchCount++
+		}
+
+		matchPercent := float64(matchCount) / 7.0 * 100.0
+
+		results = append(results, MedicamentoRecomendado{
+			Urgencia:    urg,
+			Enfermedad:  enf,
+			Cronica:     cron,
+			Pecho:       pech,
+			Respiracion: resp,
+			Medicamento: med,
+			Match:       matchPercent,
+		})
+	}
+
+	return results
+}
+
+// ============================================================================
+// PIPELINE COMPLETO DE DIAGNÓSTICO
+// ============================================================================
+
+// construirVector arma el vector de entrada del modelo a partir de las
+// features del texto y los scores de enfermedades, en el orden de
+// nombresFeatures.
+func construirVector(featuresTexto FeaturesTexto, probabilidades map[string]float64) (VectorEntrada, []float64) {
+	var entrada VectorEntrada
+	entrada.a_asma = float32(probabilidades["asma"])
+	entrada.a_bronquitis = float32(probabilidades["bronquitis"])
+	entrada.a_enfisema = float32(probabilidades["enfisema"])
+	entrada.a_apnea = float32(probabilidades["apnea"])
+	entrada.a_fibromialgia = float32(probabilidades["fibromialgia"])
+	entrada.a_migranas = float32(probabilidades["migrañas"])
+	entrada.a_reflujo = float32(probabilidades["reflujo"])
+	entrada.n_sintomas = featuresTexto.n_sintomas
+	entrada.n_cronicas = featuresTexto.n_cronicas
+	entrada.redflag_pecho = featuresTexto.redflag_pecho
+	entrada.redflag_respiracion = featuresTexto.redflag_respiracion
+	entrada.tiene_cronicas = featuresTexto.tiene_cronicas
+
+	Xdata := []float64{
+		float64(entrada.a_asma),
+		float64(entrada.a_bronquitis),
+		float64(entrada.a_enfisema),
+		float64(entrada.a_apnea),
+		float64(entrada.a_fibromialgia),
+		float64(entrada.a_migranas),
+		float64(entrada.a_reflujo),
+		float64(entrada.n_sintomas),
+		float64(entrada.n_cronicas),
+		boolToFloat(entrada.redflag_pecho),
+		boolToFloat(entrada.redflag_respiracion),
+		boolToFloat(entrada.tiene_cronicas),
+	}
+	return entrada, Xdata
+}
+
+func procesarDiagnostico(req DiagnosticoRequest) (*DiagnosticoResponse, error) {
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("INICIANDO DIAGNOSTICO MEDICO")
+	fmt.Println(strings.Repeat("=", 60))
+
+	fmt.Println("\n[PASO 1] Analisis de texto del paciente")
+	featuresTexto := analizarTexto(req.Texto)
+	fmt.Printf("  Sintomas detectados: %d\n", featuresTexto.n_sintomas)
+	fmt.Printf("  Enfermedades cronicas: %d\n", featuresTexto.n_cronicas)
+	fmt.Printf("  Red flag pecho: %v\n", featuresTexto.redflag_pecho)
+	fmt.Printf("  Red flag respiracion: %v\n", featuresTexto.redflag_respiracion)
+
+	fmt.Println("\n[PASO 2] Analisis con HuggingFace (NLP)")
+	probabilidadesHF, err := puntuadorEnfermedades()(req.Texto)
+	if err != nil {
+		return nil, fmt.Errorf("error en HuggingFace: %v", err)
+	}
+
+	entrada, Xdata := construirVector(featuresTexto, probabilidadesHF)
+
+	fmt.Println("\n[PASO 3] Clasificacion con modelo Softmax")
+
+	// el modelo se toma una sola vez: si se cambia durante la petición, esta
+	// termina con el mismo modelo
+	servido, err := modeloActivo.obtenerOCargar()
+	if err != nil {
+		return nil, fmt.Errorf("modelo Softmax no disponible. Entrenelo primero")
+	}
+	modeloClasificador := servido.Modelo
+
+	Xmat := mat.NewDense(1, len(Xdata), Xdata)
+
+	prediccion, err := modeloClasificador.Predict(Xmat)
+	if err != nil {
+		return nil, fmt.Errorf("error en clasificacion Softmax: %w", err)
+	}
+	claseSoftmax := prediccion[0]
+	servido.Drift.observar(Xmat, prediccion)
+
+	probsMat, err := modeloClasificador.PredictProba(Xmat)
+	if err != nil {
+		return nil, fmt.Errorf("error en clasificacion Softmax: %w", err)
+	}
+	probsRow := probsMat.RawRowView(0)
+	fmt.Printf("  Modelo: %s (version %s)\n", modeloClasificador.ModelType(), servido.Version)
+	fmt.Printf("  Clase predicha: %d\n", claseSoftmax)
+	fmt.Printf("  Probabilidades: ")
+	for i, p := range probsRow {
+		fmt.Printf("[%d]=%.3f ", i, p)
+	}
+	fmt.Println()
+	// alrededor de la clase decidida, que con umbrales puede no ser la más
+	// probable
+	incertidumbre := algorithms.ClassUncertainty(probsRow, claseSoftmax)
+	fmt.Printf("  Incertidumbre: entropia %.3f, margen %.3f\n", incertidumbre.Entropy, incertidumbre.Margin)
+
+	// contribucion de cada feature (peso x valor) a la clase predicha
+	// frente a la segunda clase mas probable; solo aplica al modelo lineal
+	var explicacion *algorithms.Explanation
+	if sm, ok := modeloClasificador.(*algorithms.SoftmaxRegression); ok {
+		nombres := sm.FeatureNames
+		if len(nombres) == 0 {
+			nombres = nombresFeatures
+		}
+		explicacion, err = sm.Explain(Xdata, nombres)
+		if err != nil {
+			fmt.Println("  No se pudo explicar la prediccion:", err)
+			explicacion = nil
+		} else {
+			for _, c := range explicacion.Contributions {
+				if c.Contribution != 0 {
+					fmt.Printf("  %s contribuyo %+.3f\n", c.Feature, c.Contribution)
+				}
+			}
+		}
+	}
+
+	// con un ensamble se reporta el voto de cada miembro; si no coinciden
+	// la prediccion se marca como de baja confianza
+	var votacion *algorithms.EnsembleVote
+	if ens, ok := modeloClasificador.(*algorithms.Ensemble); ok {
+		votos, err := ens.Votes(Xmat)
+		if err != nil {
+			return nil, fmt.Errorf("error en votacion del ensamble: %w", err)
+		}
+		votacion = &votos[0]
+		for _, m := range votacion.Members {
+			fmt.Printf("  Miembro %s (peso %.2f): clase %d\n", m.ModelType, m.Weight, m.Class)
+		}
+		fmt.Printf("  Acuerdo: %.2f\n", votacion.Agreement)
+	}
+
+	// el clasificador predice el código de urgencia; la enfermedad es la del
+	// score a_* dominante del puntuador
+	fmt.Println("\n[PASO 4] Mapeo a diagnostico medico")
+	if claseSoftmax < 0 || claseSoftmax >= len(dataset.UrgencyLevels) {
+		return nil, fmt.Errorf("el modelo predijo la clase %d y solo hay %d niveles de urgencia",
+			claseSoftmax, len(dataset.UrgencyLevels))
+	}
+	diagnostico := dataset.BroncoClasses[dataset.DominantDisease(Xdata)]
+	diagnostico.Urgency = dataset.UrgencyLevels[claseSoftmax]
+
+	fmt.Printf("  Enfermedad: %s\n", diagnostico.Disease)
+	fmt.Printf("  Urgencia: %s\n", diagnostico.Urgency)
+	fmt.Printf("  Cronica: %s\n", diagnostico.Chronic)
+
+	pecho := "pecho_no"
+	if entrada.redflag_pecho {
+		pecho = "pecho_si"
+	}
+	respiracion := "resp_no"
+	if entrada.redflag_respiracion {
+		respiracion = "resp_si"
+	}
+
+	fmt.Println("\n[PASO 5] Obteniendo medicamentos contraindicados desde Prolog")
+	medicamentosContraindicados := obtenerMedicamentosContraindicados(
+		maquinaProlog,
+		diagnostico.Urgency,
+		diagnostico.Disease,
+		diagnostico.Chronic,
+		pecho,
+		respiracion,
+	)
+
+	fmt.Println("\n[PASO 6] Analizando medicamentos contraindicados")
+	totalContraindicados := len(medicamentosContraindicados)
+	fmt.Printf("  Total medicamentos contraindicados: %d\n", totalContraindicados)
+
+	var advertencias []string
+
+	if diagnostico.Disease != "ninguna" {
+		advertencias = append(advertencias,
+			fmt.Sprintf("Diagnostico: %s", diagnostico.Disease))
+	}
+
+	if diagnostico.Urgency == "alta" {
+		advertencias = append(advertencias,
+			"URGENCIA ALTA: Se recomienda atencion medica inmediata")
+	}
+
+	if featuresTexto.redflag_pecho {
+		advertencias = append(advertencias,
+			"RED FLAG: Dolor o presion en el pecho detectado")
+	}
+	if featuresTexto.redflag_respiracion {
+		advertencias = append(advertencias,
+			"RED FLAG: Dificultad respiratoria severa detectada")
+	}
+
+	if votacion != nil && votacion.LowConfidence {
+		advertencias = append(advertencias,
+			fmt.Sprintf("BAJA CONFIANZA: los modelos no coinciden (acuerdo %.0f%%), revisar manualmente",
+				votacion.Agreement*100))
+	}
+
+	if totalContraindicados > 0 {
+		advertencias = append(advertencias,
+			fmt.Sprintf("IMPORTANTE: %d medicamentos estan contraindicados para esta enfermedad",
+				totalContraindicados))
+	}
+
+	respuesta := &DiagnosticoResponse{
+		ID:                        nuevoIDDiagnostico(),
+		EnfermedadDetectada:       diagnostico.Disease,
+		NivelUrgencia:             diagnostico.Urgency,
+		ProbabilidadesHuggingFace: probabilidadesHF,
+		ClaseSoftmax:              claseSoftmax,
+		Explicacion:               explicacion,
+		VotacionModelos:           votacion,
+		Incertidumbre:             incertidumbre,
+		MedicamentosEvaluados:     medicamentosContraindicados,
+		TotalContraindicados:      totalContraindicados,
+		Advertencias:              advertencias,
+		TextoRecibido:             req.Texto,
+	}
+
+	// los diagnósticos en los que el modelo duda van a la cola de revisión,
+	// para que los clínicos etiqueten primero los que más aportan
+	fmt.Println("\n[PASO 7] Cola de revision")
+	if revision.requiereRevision(incertidumbre) {
+		err := revision.encolar(CasoRevision{
+			ID:             respuesta.ID,
+			Fecha:          time.Now().UTC(),
+			Incertidumbre:  incertidumbre,
+			Modelo:         modeloClasificador.ModelType(),
+			Version:        servido.Version,
+			Clase:          claseSoftmax,
+			Enfermedad:     diagnostico.Disease,
+			Urgencia:       diagnostico.Urgency,
+			Probabilidades: append([]float64(nil), probsRow...),
+			Features:       Xdata,
+			Texto:          auditoria.textoGuardado(req.Texto),
+			TextoModo:      auditoria.Texto,
+		})
+		if err != nil {
+			fmt.Println("  No se pudo encolar el diagnostico:", err)
+		} else {
+			respuesta.EnRevision = true
+			respuesta.Advertencias = append(respuesta.Advertencias,
+				fmt.Sprintf("BAJA CONFIANZA: entropia %.2f, margen %.2f entre las dos clases mas probables; caso enviado a revision clinica",
+					incertidumbre.Entropy, incertidumbre.Margin))
+			fmt.Printf("  Encolado con prioridad %.3f\n", incertidumbre.Priority())
+		}
+	} else {
+		fmt.Println("  No requiere revision")
+	}
+
+	fmt.Println("\n[PASO 8] Registro de auditoria")
+	contraindicados := make([]string, 0, totalContraindicados)
+	for _, m := range medicamentosContraindicados {
+		contraindicados = append(contraindicados, m.Medicamento)
+	}
+	err = auditoria.registrar(RegistroDiagnostico{
+		ID:                 respuesta.ID,
+		Fecha:              time.Now().UTC(),
+		Modelo:             modeloClasificador.ModelType(),
+		Version:            servido.Version,
+		Features:           Xdata,
+		Clase:              claseSoftmax,
+		Probabilidades:     append([]float64(nil), probsRow...),
+		Enfermedad:         diagnostico.Disease,
+		Urgencia:           diagnostico.Urgency,
+		RedflagPecho:       featuresTexto.redflag_pecho,
+		RedflagRespiracion: featuresTexto.redflag_respiracion,
+		Contraindicados:    contraindicados,
+		Advertencias:       respuesta.Advertencias,
+		Texto:              req.Texto,
+	})
+	if err != nil {
+		fmt.Println("  No se pudo registrar el diagnostico:", err)
+	} else {
+		fmt.Println("  Id:", respuesta.ID)
+	}
+
+	fmt.Println("\n" + strings.Repeat("=", 60))
+	fmt.Println("DIAGNOSTICO COMPLETADO")
+	fmt.Println(strings.Repeat("=", 60) + "\n")
+
+	return respuesta, nil
+}
+
+// ============================================================================
+// SERVIDOR HTTP (FIBER)
+// ============================================================================
+
+// Run levanta el servidor HTTP en addr (por ejemplo ":8080") y bloquea
+// hasta que termina.
+func Run(addr string) error {
+
+	godotenv.Load()
+	app := fiber.New(fiber.Config{
+		ErrorHandler: func(c *fiber.Ctx, err error) error {
+			return c.Status(500).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		},
+	})
+
+	app.Use(func(c *fiber.Ctx) error {
+		c.Set("Access-Control-Allow-Origin", "*")
+		c.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		// los tokens de soloAdmin y soloClinico viajan en estos encabezados
+		c.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Admin-Token, X-Clinico-Token")
+
+		if c.Method() == fiber.MethodOptions {
+			return c.SendStatus(fiber.StatusOK)
+		}
+
+		return c.Next()
+	})
+
+	fmt.Println("Iniciando servidor UniMatch...")
+
+	fmt.Println("Cargando base de conocimiento Prolog...")
+	programa := cargarProlog("./prolog/conocimiento.pl")
+	maquinaProlog = golog.NewMachine().Consult(programa)
+	fmt.Println("Prolog cargado")
+
+	if err := registroModelos.LoadKeysFromEnv(); err != nil {
+		return fmt.Errorf("claves de firma: %w", err)
+	}
+	if registroModelos.VerifyKey != nil {
+		fmt.Println("Verificacion de firmas activa: se rechazan modelos sin firma valida")
+		if registroModelos.SigningKey == nil {
+			fmt.Println("Sin UNMATCH_SIGNING_KEY los modelos entrenados por la API no se podran activar")
+		}
+	}
+
+	fmt.Println("Cargando modelo Softmax...")
+	if servido, err := modeloActivo.recargar(); err == nil {
+		if servido.Version != "" {
+			fmt.Printf("Modelo %s cargado (version %s)\n", servido.Modelo.ModelType(), servido.Version)
+		} else {
+			fmt.Printf("Modelo %s cargado desde %s\n", servido.Modelo.ModelType(), softmaxModelPath)
+		}
+	} else if errorIntegridad(err) {
+		fmt.Println("Modelo rechazado:", err)
+	} else {
+		fmt.Println("Modelo Softmax no encontrado. Entrenelo via /softmax/train")
+	}
+
+	configuracion, err := configurarAuditoria()
+	if err != nil {
+		return err
+	}
+	auditoria = configuracion
+	if auditoria.Path != "" {
+		fmt.Printf("Log de auditoria en %s (texto %s)\n", auditoria.Path, auditoria.Texto)
+		go auditoria.mantener()
+	}
+
+	if err := configurarTrabajos(); err != nil {
+		return err
+	}
+
+	intervalo, err := intervaloVigilancia()
+	if err != nil {
+		return err
+	}
+	if intervalo > 0 {
+		fmt.Printf("Vigilando %s cada %s\n", softmaxModelPath, intervalo)
+		go modeloActivo.vigilar(softmaxModelPath, intervalo, nil)
+	}
+
+	cola, err := configurarRevision()
+	if err != nil {
+		return err
+	}
+	revision = cola
+	if revision.Path != "" {
+		fmt.Printf("Cola de revision en %s (%d casos, entropia > %.2f o margen < %.2f)\n",
+			revision.Path, len(revision.casos), revision.Umbrales.MaxEntropy, revision.Umbrales.MinMargin)
+	}
+
+	app.Get("/", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{
+			"servicio":    "UniMatch Medical Diagnosis API",
+			"version":     "3.0",
+			"estado":      "activo",
+			"descripcion": "Sistema que evalua medicamentos y detecta contraindicaciones",
+			"flujo": []string{
+				"1. Analisis de texto (sintomas y red flags)",
+				"2. HuggingFace (probabilidades de enfermedades)",
+				"3. Softmax (clasificacion final)",
+				"4. Prolog (entrega todos los medicamentos)",
+				"5. Evaluacion (marca cuales estan contraindicados)",
+			},
+			"endpoints": []string{
+				"POST /diagnostico - Diagnostico completo con evaluacion de medicamentos",
+				"POST /softmax/train - Entrenar un modelo candidato en segundo plano (softmax, decision_tree, gaussian_nb, knn, mlp) (admin)",
+				"GET /jobs - Trabajos de entrenamiento",
+				"GET /jobs/:id - Estado, iteracion y loss de un entrenamiento",
+				"GET /jobs/:id/eventos - Progreso del entrenamiento en vivo (Server-Sent Events)",
+				"POST /jobs/:id/cancelar - Cancelar un entrenamiento (admin)",
+				"POST /softmax/predict - Prediccion con Softmax",
+				"GET /softmax/importance - Pesos e importancia de cada feature",
+				"POST /diagnostico/:id/feedback - Registrar la clase correcta de un diagnostico (clinico)",
+				"GET /feedback - Correcciones registradas (clinico)",
+				"GET /revision - Cola de diagnosticos inciertos, los mas inciertos primero (clinico)",
+				"GET /revision/:id - Caso de la cola de revision (clinico)",
+				"POST /revision/:id/etiquetar - Etiquetar un caso de la cola (clinico)",
+				"POST /revision/:id/cerrar - Cerrar un caso de la cola (clinico)",
+				"GET /auditoria/exportar - Diagnosticos registrados en JSONL o CSV de entrenamiento (admin)",
+				"GET /auditoria/:id - Registro de un diagnostico (admin)",
+				"GET /monitoreo/drift - Drift de las entradas y clases predichas frente al entrenamiento (PSI / KS)",
+				"POST /monitoreo/drift/reiniciar - Reiniciar las estadisticas de drift (admin)",
+				"GET /modelos - Versiones del registro de modelos",
+				"POST /modelos/:id/promover - Activar una version (admin)",
+				"POST /modelos/rollback - Volver a la version activa anterior (admin)",
+				"POST /modelos/recargar - Volver a leer el modelo activo del disco (admin)",
+				"POST /modelos/actualizar - Continuar el entrenamiento del modelo servido con el feedback de los clinicos (admin)",
+			},
+		})
+	})
+
+	app.Post("/diagnostico", func(c *fiber.Ctx) error {
+		var req DiagnosticoRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error":   "Error al parsear JSON de entrada",
+				"detalle": err.Error(),
+			})
+		}
+
+		if req.Texto == "" {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "El campo 'texto' es requerido",
+			})
+		}
+
+		respuesta, err := procesarDiagnostico(req)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Error al procesar diagnostico",
+				"detalle": err.Error(),
+			})
+		}
+
+		///RPA
+
+		exePath, _ := os.Getwd()
+
+		cmd := exec.Command(
+			"powershell",
+			"-NoProfile",
+			"-ExecutionPolicy", "Bypass",
+			"-File", "rpa.ps1",
+		)
+
+		cmd.Dir = exePath
+
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			fmt.Println("Error ejecutando PowerShell:", err)
+		}
+
+		fmt.Println(string(out))
+
+		///
+
+		return c.JSON(respuesta)
+	})
+
+	app.Post("/softmax/train", soloAdmin, func(c *fiber.Ctx) error {
+		var req struct {
+			X         [][]float64 `json:"x"`
+			Y         []int       `json:"y"`
+			Modelo    string      `json:"modelo"`
+			Lr        float64     `json:"lr"`
+			NIter     int         `json:"n_iter"`
+			RegLambda float64     `json:"reg_lambda"`
+			Seed      *int64      `json:"seed"`
+			Penalty   string      `json:"penalty"`
+			L1Ratio   float64     `json:"l1_ratio"`
+			Features  []string    `json:"feature_names"`
+			MaxDepth  int         `json:"max_depth"`
+			MinSplit  int         `json:"min_samples_split"`
+			K         int         `json:"k"`
+			Hidden    []int       `json:"hidden"`
+		}
+
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Error al parsear entrada"})
+		}
+
+		if len(req.X) == 0 || len(req.Y) == 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "X e Y son requeridos"})
+		}
+
+		if len(req.X) != len(req.Y) {
+			return c.Status(400).JSON(fiber.Map{"error": "X e Y deben tener el mismo tamaño"})
+		}
+
+		Xmat, err := slice2DToDense(req.X)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		if req.NIter < 0 || req.NIter > maxIteraciones {
+			return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("n_iter debe estar entre 0 (valor por defecto) y %d", maxIteraciones)})
+		}
+		for _, h := range req.Hidden {
+			if h > maxNeuronas {
+				return c.Status(400).JSON(fiber.Map{"error": fmt.Sprintf("cada capa de hidden admite como mucho %d neuronas", maxNeuronas)})
+			}
+		}
+
+		var model algorithms.Classifier
+		params := fiber.Map{}
+
+		switch req.Modelo {
+		case "", algorithms.ModelSoftmax:
+			lr := req.Lr
+			if lr == 0 {
+				lr = 0.1
+			}
+			nIter := req.NIter
+			if nIter == 0 {
+				nIter = 2000
+			}
+			reg := req.RegLambda
+			if reg == 0 {
+				reg = 1e-3
+			}
+
+			sm := algorithms.NewSoftmaxRegression(lr, nIter, reg)
+			if req.Seed != nil {
+				sm.Seed = *req.Seed
+			}
+			sm.Penalty = req.Penalty
+			sm.L1Ratio = req.L1Ratio
+			if len(req.Features) > 0 {
+				if len(req.Features) != len(req.X[0]) {
+					return c.Status(400).JSON(fiber.Map{"error": "feature_names debe tener un nombre por columna de X"})
+				}
+				sm.FeatureNames = req.Features
+			} else if len(req.X[0]) == len(nombresFeatures) {
+				sm.FeatureNames = nombresFeatures
+			}
+			model = sm
+			params = fiber.Map{
+				"lr":         lr,
+				"n_iter":     nIter,
+				"reg_lambda": reg,
+				"seed":       sm.Seed,
+				"penalty":    sm.Penalty,
+				"l1_ratio":   sm.L1Ratio,
+			}
+		default:
+			model, err = algorithms.NewClassifier(req.Modelo)
+			if err != nil {
+				return c.Status(statusModelo(err)).JSON(fiber.Map{"error": err.Error()})
+			}
+			switch m := model.(type) {
+			case *algorithms.DecisionTree:
+				if req.MaxDepth > 0 {
+					m.MaxDepth = req.MaxDepth
+				}
+				if req.MinSplit > 0 {
+					m.MinSamplesSplit = req.MinSplit
+				}
+				params = fiber.Map{"max_depth": m.MaxDepth, "min_samples_split": m.MinSamplesSplit}
+			case *algorithms.KNN:
+				if req.K != 0 {
+					m.K = req.K
+				}
+				params = fiber.Map{"k": m.K}
+			case *algorithms.MLP:
+				if len(req.Hidden) > 0 {
+					m.Hidden = req.Hidden
+				}
+				if req.Lr > 0 {
+					m.Lr = req.Lr
+				}
+				if req.NIter > 0 {
+					m.NIter = req.NIter
+				}
+				if req.RegLambda > 0 {
+					m.RegLambda = req.RegLambda
+				}
+				if req.Seed != nil {
+					m.Seed = *req.Seed
+				}
+				params = fiber.Map{
+					"hidden":     m.Hidden,
+					"lr":         m.Lr,
+					"n_iter":     m.NIter,
+					"reg_lambda": m.RegLambda,
+					"seed":       m.Seed,
+				}
+			}
+		}
+
+		// los errores de parámetros o de datos se responden ahora y no como
+		// un trabajo fallido
+		if err := algorithms.ValidateFit(model, Xmat, req.Y); err != nil {
+			return c.Status(statusModelo(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		gates, err := algorithms.LoadPromotionGates(algorithms.DefaultGatesPath)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		// el candidato se evalúa sobre el conjunto de validación del
+		// servidor, nunca sobre datos enviados por quien lo entrena
+		XTrain, yTrain := Xmat, req.Y
+		XHold, yHold, err := conjuntoValidacion(gates)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		// el entrenamiento corre como trabajo en segundo plano: la respuesta
+		// trae el id para consultar GET /jobs/:id
+		trabajo, err := trabajosEntrenamiento.iniciar(model.ModelType(), func(ctx context.Context, progreso algorithms.ProgressFunc) (fiber.Map, error) {
+			fmt.Printf("Entrenando modelo %s...\n", model.ModelType())
+			// puntos de control con loss y métricas sobre el conjunto de
+			// validación, para GET /jobs/:id/eventos
+			progreso = algorithms.ValidationProgress(model, XHold, yHold, 0, progreso)
+			if err := algorithms.FitContext(ctx, model, XTrain, yTrain, progreso); err != nil {
+				return nil, err
+			}
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			acc, err := algorithms.Accuracy(model, XTrain, yTrain)
+			if err != nil {
+				return nil, err
+			}
+
+			// el modelo entrenado es un candidato: se compara con el modelo
+			// actual sobre el conjunto de validación y solo se activa si pasa
+			// los gates (o si un admin lo promueve con /modelos/:id/promover)
+			var actual algorithms.Classifier
+			if servido := modeloActivo.obtener(); servido != nil {
+				actual = servido.Modelo
+			}
+			evaluacion, err := algorithms.EvaluateCandidate(model, actual, XHold, yHold, gates)
+			if err != nil {
+				return nil, err
+			}
+			manifest := algorithms.NewTrainingManifest(model, XTrain, yTrain, acc)
+			version, err := registroModelos.Register(model, manifest)
+			if err != nil {
+				return nil, fmt.Errorf("error al guardar modelo: %w", err)
+			}
+			if err := registroModelos.SaveEvaluation(version.ID, evaluacion); err != nil {
+				return nil, fmt.Errorf("error al guardar evaluacion: %w", err)
+			}
+
+			promovido := evaluacion.Passed && gates.AutoPromote
+			mensaje := "Modelo candidato registrado; requiere aprobacion de un admin"
+			if promovido {
+				// la ruta es de admin: la promoción automática firma
+				err := modeloActivo.activar(model, version.ID, "entrenamiento", func() error {
+					if err := registroModelos.Sign(version.ID); err != nil {
+						return err
+					}
+					return registroModelos.Promote(version.ID)
+				})
+				if err != nil {
+					return nil, fmt.Errorf("error al activar modelo: %w", err)
+				}
+				mensaje = "Modelo entrenado exitosamente y activado"
+			}
+			fmt.Printf("Modelo guardado como version %s (promovido: %v)\n", version.ID, promovido)
+
+			respuesta := fiber.Map{
+				"mensaje":    mensaje,
+				"modelo":     model.ModelType(),
+				"version":    version.ID,
+				"promovido":  promovido,
+				"evaluacion": evaluacion,
+				"accuracy":   acc,
+				"manifest":   manifest,
+			}
+			for k, v := range params {
+				respuesta[k] = v
+			}
+			return respuesta, nil
+		})
+
+		if errors.Is(err, errSinCapacidad) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+			"mensaje": "Entrenamiento iniciado",
+			"trabajo": trabajo,
+			"url":     "/jobs/" + trabajo.ID,
+		})
+	})
+
+	app.Get("/jobs", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"trabajos": trabajosEntrenamiento.listar()})
+	})
+
+	app.Get("/jobs/:id", func(c *fiber.Ctx) error {
+		trabajo, ok := trabajosEntrenamiento.obtener(c.Params("id"))
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{"error": "Trabajo no encontrado"})
+		}
+		return c.JSON(trabajo)
+	})
+
+	app.Get("/jobs/:id/eventos", eventosTrabajo)
+
+	app.Post("/jobs/:id/cancelar", soloAdmin, func(c *fiber.Ctx) error {
+		trabajo, ok := trabajosEntrenamiento.cancelar(c.Params("id"))
+		if !ok {
+			return c.Status(404).JSON(fiber.Map{"error": "Trabajo no encontrado"})
+		}
+		return c.JSON(trabajo)
+	})
+
+	app.Post("/softmax/predict", func(c *fiber.Ctx) error {
+		var req struct {
+			X [][]float64 `json:"x"`
+		}
+
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Error al parsear entrada"})
+		}
+
+		if len(req.X) == 0 {
+			return c.Status(400).JSON(fiber.Map{"error": "X es requerido"})
+		}
+
+		servido, err := modeloActivo.obtenerOCargar()
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "Modelo no entrenado. Primero llame a /softmax/train",
+			})
+		}
+		modeloClasificador := servido.Modelo
+
+		Xmat, err := slice2DToDense(req.X)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		yPred, err := modeloClasificador.Predict(Xmat)
+		if err != nil {
+			return c.Status(statusModelo(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+		servido.Drift.observar(Xmat, yPred)
+		probsMat, err := modeloClasificador.PredictProba(Xmat)
+		if err != nil {
+			return c.Status(statusModelo(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+		probs := denseTo2D(probsMat)
+
+		return c.JSON(fiber.Map{
+			"modelo":  modeloClasificador.ModelType(),
+			"version": servido.Version,
+			"y_pred":  yPred,
+			"probs":   probs,
+		})
+	})
+
+	app.Get("/softmax/importance", func(c *fiber.Ctx) error {
+		servido := modeloActivo.obtener()
+		if servido == nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "Modelo no entrenado. Primero llame a /softmax/train",
+			})
+		}
+		modeloClasificador := servido.Modelo
+		softmaxModel, ok := modeloClasificador.(*algorithms.SoftmaxRegression)
+		if !ok {
+			return c.Status(400).JSON(fiber.Map{
+				"error": fmt.Sprintf("El modelo activo (%s) no es lineal; la importancia solo aplica a softmax", modeloClasificador.ModelType()),
+			})
+		}
+
+		nombres := softmaxModel.FeatureNames
+		if len(nombres) == 0 {
+			nombres = nombresFeatures
+		}
+		importancias, err := softmaxModel.FeatureImportances(nombres)
+		if err != nil {
+			return c.Status(statusModelo(err)).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		var anuladas []string
+		for _, imp := range importancias {
+			if imp.Zeroed {
+				anuladas = append(anuladas, imp.Feature)
+			}
+		}
+
+		return c.JSON(fiber.Map{
+			"penalty":           softmaxModel.Penalty,
+			"reg_lambda":        softmaxModel.RegLambda,
+			"l1_ratio":          softmaxModel.L1Ratio,
+			"features":          importancias,
+			"features_anuladas": anuladas,
+		})
+	})
+
+	app.Post("/diagnostico/:id/feedback", soloClinico, registrarFeedback)
+	app.Get("/feedback", soloClinico, listarFeedback)
+
+	app.Get("/revision", soloClinico, listarRevision)
+	app.Get("/revision/:id", soloClinico, obtenerCasoRevision)
+	app.Post("/revision/:id/etiquetar", soloClinico, etiquetarCaso)
+	app.Post("/revision/:id/cerrar", soloClinico, cerrarCaso)
+
+	app.Get("/auditoria/exportar", soloAdmin, exportarAuditoria)
+	app.Get("/auditoria/:id", soloAdmin, obtenerRegistroAuditoria)
+
+	app.Get("/monitoreo/drift", reporteDrift)
+	app.Post("/monitoreo/drift/reiniciar", soloAdmin, reiniciarDrift)
+
+	app.Get("/modelos", func(c *fiber.Ctx) error {
+		versiones, err := registroModelos.List()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		activa, err := registroModelos.Active()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{
+			"activa":    activa,
+			"versiones": versiones,
+		})
+	})
+
+	// errorVersion responde al error de firmar o cargar una version.
+	errorVersion := func(c *fiber.Ctx, err error) error {
+		if errors.Is(err, algorithms.ErrVersionNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		if errorIntegridad(err) {
+			return c.Status(409).JSON(fiber.Map{"error": "Version rechazada: " + err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "No se pudo cargar la version: " + err.Error()})
+	}
+
+	// activarVersion carga id y lo deja como modelo servido; si el
+	// artefacto no carga, la version activa no cambia.
+	activarVersion := func(c *fiber.Ctx, id string, cambiar func() error) error {
+		model, err := registroModelos.Load(id)
+		if err != nil {
+			return errorVersion(c, err)
+		}
+		if err := modeloActivo.activar(model, id, "registro", cambiar); err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		fmt.Println("Version activa:", id)
+		return c.JSON(fiber.Map{
+			"mensaje": "Version activada",
+			"activa":  id,
+			"modelo":  model.ModelType(),
+		})
+	}
+
+	app.Post("/modelos/:id/promover", soloAdmin, func(c *fiber.Ctx) error {
+		// copia: la version queda guardada en el modelo servido y Params apunta
+		// al buffer de la petición
+		id := utils.CopyString(c.Params("id"))
+		// las versiones se registran sin firmar; se firman aquí, al
+		// promoverlas un admin
+		if err := registroModelos.Sign(id); err != nil {
+			return errorVersion(c, err)
+		}
+		return activarVersion(c, id, func() error { return registroModelos.Promote(id) })
+	})
+
+	app.Post("/modelos/rollback", soloAdmin, func(c *fiber.Ctx) error {
+		id, err := registroModelos.Previous()
+		if errors.Is(err, algorithms.ErrVersionNotFound) {
+			return c.Status(409).JSON(fiber.Map{"error": "No hay una version anterior a la cual volver"})
+		}
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		return activarVersion(c, id, func() error {
+			_, err := registroModelos.Rollback()
+			return err
+		})
+	})
+
+	app.Post("/modelos/recargar", soloAdmin, func(c *fiber.Ctx) error {
+		servido, err := modeloActivo.recargar()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "No se pudo recargar el modelo: " + err.Error()})
+		}
+		fmt.Println("Modelo recargado:", servido.Version)
+		return c.JSON(fiber.Map{
+			"mensaje": "Modelo recargado",
+			"activa":  servido.Version,
+			"origen":  servido.Origen,
+			"modelo":  servido.Modelo.ModelType(),
+		})
+	})
+
+	app.Post("/modelos/actualizar", soloAdmin, actualizarModelo)
+
+	fmt.Println("\nServidor UniMatch activo en", addr)
+	fmt.Println("Endpoints disponibles:")
+	fmt.Println("   GET  /")
+	fmt.Println("   POST /diagnostico")
+	fmt.Println("   POST /softmax/train")
+	fmt.Println("   GET  /jobs")
+	fmt.Println("   GET  /jobs/:id")
+	fmt.Println("   GET  /jobs/:id/eventos")
+	fmt.Println("   POST /jobs/:id/cancelar")
+	fmt.Println("   POST /softmax/predict")
+	fmt.Println("   GET  /softmax/importance")
+	fmt.Println("   POST /diagnostico/:id/feedback")
+	fmt.Println("   GET  /feedback")
+	fmt.Println("   GET  /revision")
+	fmt.Println("   GET  /revision/:id")
+	fmt.Println("   POST /revision/:id/etiquetar")
+	fmt.Println("   POST /revision/:id/cerrar")
+	fmt.Println("   GET  /auditoria/exportar")
+	fmt.Println("   GET  /auditoria/:id")
+	fmt.Println("   GET  /monitoreo/drift")
+	fmt.Println("   POST /monitoreo/drift/reiniciar")
+	fmt.Println("   GET  /modelos")
+	fmt.Println("   POST /modelos/:id/promover")
+	fmt.Println("   POST /modelos/rollback")
+	fmt.Println("   POST /modelos/recargar")
+	fmt.Println("   POST /modelos/actualizar")
+	fmt.Println()
+
+	if err := app.Listen(addr); err != nil {
+		return fmt.Errorf("error al iniciar servidor: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,412 @@
+package server
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"unmatch/backend/dataset"
+)
+
+// RegistroDiagnostico es una línea del log de auditoría: todo lo que se
+// necesita para revisar un /diagnostico después, o para reentrenar con él.
+type RegistroDiagnostico struct {
+	ID                 string    `json:"id"`
+	Fecha              time.Time `json:"fecha"`
+	Modelo             string    `json:"modelo"`
+	Version            string    `json:"version,omitempty"`
+	Features           []float64 `json:"features"` // en el orden de nombresFeatures
+	Clase              int       `json:"clase"`
+	Probabilidades     []float64 `json:"probabilidades"`
+	Enfermedad         string    `json:"enfermedad"`
+	Urgencia           string    `json:"urgencia"`
+	RedflagPecho       bool      `json:"redflag_pecho"`
+	RedflagRespiracion bool      `json:"redflag_respiracion"`
+	Contraindicados    []string  `json:"contraindicados"`
+	Advertencias       []string  `json:"advertencias"`
+	Texto              string    `json:"texto,omitempty"`
+	TextoModo          string    `json:"texto_modo"`
+}
+
+// Qué se guarda del texto del paciente (UNMATCH_AUDITORIA_TEXTO).
+const (
+	textoCompleto  = "completo"
+	textoRedactado = "redactado"
+	textoOmitido   = "omitido"
+)
+
+const rutaAuditoriaDefault = "./logs/diagnosticos.jsonl"
+
+// logAuditoria agrega los diagnósticos a un archivo JSONL. Escribir y podar
+// se serializan con mu, así que podar nunca pierde una línea nueva.
+type logAuditoria struct {
+	Path      string        // "" desactiva el log
+	Texto     string        // textoCompleto, textoRedactado o textoOmitido
+	Retencion time.Duration // los registros más viejos se borran; 0 los guarda siempre
+	mu        sync.Mutex
+}
+
+var auditoria = &logAuditoria{}
+
+// configurarAuditoria lee la configuración del log de las variables de
+// entorno:
+//
+//   - UNMATCH_AUDITORIA: ruta del archivo (por defecto
+//     ./logs/diagnosticos.jsonl); "off" lo desactiva.
+//   - UNMATCH_AUDITORIA_TEXTO: completo, redactado (por defecto) u omitido.
+//   - UNMATCH_AUDITORIA_DIAS: días que se guarda cada registro; 0 o vacío
+//     es para siempre.
+func configurarAuditoria() (*logAuditoria, error) {
+	l := &logAuditoria{Path: os.Getenv("UNMATCH_AUDITORIA"), Texto: os.Getenv("UNMATCH_AUDITORIA_TEXTO")}
+	switch l.Path {
+	case "":
+		l.Path = rutaAuditoriaDefault
+	case "off":
+		l.Path = ""
+	}
+	switch l.Texto {
+	case "":
+		l.Texto = textoRedactado
+	case textoCompleto, textoRedactado, textoOmitido:
+	default:
+		return nil, fmt.Errorf("UNMATCH_AUDITORIA_TEXTO=%q: debe ser completo, redactado u omitido", l.Texto)
+	}
+	if valor := os.Getenv("UNMATCH_AUDITORIA_DIAS"); valor != "" {
+		dias, err := strconv.Atoi(valor)
+		if err != nil || dias < 0 {
+			return nil, fmt.Errorf("UNMATCH_AUDITORIA_DIAS=%q no es un numero de dias valido", valor)
+		}
+		l.Retencion = time.Duration(dias) * 24 * time.Hour
+	}
+	return l, nil
+}
+
+// nuevoIDDiagnostico devuelve un id único para un diagnóstico.
+func nuevoIDDiagnostico() string {
+	var b [6]byte
+	rand.Read(b[:])
+	return "dx-" + time.Now().UTC().Format("20060102-150405") + "-" + hex.EncodeToString(b[:])
+}
+
+var (
+	patronEmail  = regexp.MustCompile(`[\w.+-]+@[\w-]+(\.[\w-]+)+`)
+	patronNumero = regexp.MustCompile(`\+?\d[\d .-]{5,}\d`)
+)
+
+// redactarTexto oculta correos y números largos (teléfonos, documentos de
+// identidad). No detecta nombres: si el texto puede traerlos, usar
+// textoOmitido.
+func redactarTexto(texto string) string {
+	texto = patronEmail.ReplaceAllString(texto, "[email]")
+	return patronNumero.ReplaceAllString(texto, "[numero]")
+}
+
+// textoGuardado devuelve lo que se guarda del texto del paciente según
+// l.Texto.
+func (l *logAuditoria) textoGuardado(texto string) string {
+	switch l.Texto {
+	case textoRedactado:
+		return redactarTexto(texto)
+	case textoOmitido:
+		return ""
+	}
+	return texto
+}
+
+// registrar agrega r al log, con el texto según l.Texto.
+func (l *logAuditoria) registrar(r RegistroDiagnostico) error {
+	if l.Path == "" {
+		return nil
+	}
+	r.TextoModo = l.Texto
+	r.Texto = l.textoGuardado(r.Texto)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return agregarJSONL(l.Path, r)
+}
+
+// agregarJSONL agrega v como una línea JSON al final de path, creando el
+// archivo (solo legible por el dueño) y su carpeta si no existen.
+func agregarJSONL(path string, v any) error {
+	linea, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(linea, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// recorrer llama a fn con cada registro del log, en orden. Las líneas que
+// no se pueden leer se saltan.
+func (l *logAuditoria) recorrer(fn func(RegistroDiagnostico) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.recorrerSinBloqueo(fn)
+}
+
+func (l *logAuditoria) recorrerSinBloqueo(fn func(RegistroDiagnostico) error) error {
+	return leerJSONL(l.Path, func(linea []byte) error {
+		var r RegistroDiagnostico
+		if json.Unmarshal(linea, &r) != nil {
+			return nil
+		}
+		return fn(r)
+	})
+}
+
+// leerJSONL llama a fn con cada línea de path; un archivo que no existe no
+// tiene líneas.
+func leerJSONL(path string, fn func(linea []byte) error) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		if err := fn(sc.Bytes()); err != nil {
+			return err
+		}
+	}
+	return sc.Err()
+}
+
+// podar reescribe el log sin los registros anteriores a ahora - Retencion.
+// Las líneas que no se pueden leer se conservan tal cual, para revisarlas a
+// mano. Devuelve cuántos registros borró y cuántas líneas ilegibles hay.
+func (l *logAuditoria) podar(ahora time.Time) (borrados, ilegibles int, err error) {
+	if l.Path == "" || l.Retencion == 0 {
+		return 0, 0, nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limite := ahora.Add(-l.Retencion)
+	var conservados []byte
+	err = leerJSONL(l.Path, func(linea []byte) error {
+		var r RegistroDiagnostico
+		if json.Unmarshal(linea, &r) != nil {
+			ilegibles++
+		} else if r.Fecha.Before(limite) {
+			borrados++
+			return nil
+		}
+		conservados = append(append(conservados, linea...), '\n')
+		return nil
+	})
+	if err != nil || borrados == 0 {
+		return 0, ilegibles, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(l.Path), "."+filepath.Base(l.Path)+".tmp-*")
+	if err != nil {
+		return 0, ilegibles, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(conservados); err != nil {
+		tmp.Close()
+		return 0, ilegibles, err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return 0, ilegibles, err
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, ilegibles, err
+	}
+	return borrados, ilegibles, os.Rename(tmp.Name(), l.Path)
+}
+
+// mantener poda el log al arrancar y luego una vez al día. No termina
+// nunca; se llama en una goroutine.
+func (l *logAuditoria) mantener() {
+	for {
+		borrados, ilegibles, err := l.podar(time.Now().UTC())
+		if err != nil {
+			fmt.Println("No se pudo podar el log de auditoria:", err)
+		} else if borrados > 0 {
+			fmt.Printf("Log de auditoria: %d registros vencidos borrados\n", borrados)
+		}
+		if ilegibles > 0 {
+			fmt.Printf("Log de auditoria: %d lineas ilegibles en %s (se conservan)\n", ilegibles, l.Path)
+		}
+		time.Sleep(24 * time.Hour)
+	}
+}
+
+// escribirCSVBronco escribe las filas con exactamente las columnas de
+// bronco_dataset.csv, para que `unmatch train` las lea tal cual (sin
+// -schema, toda columna extra sería una feature). Las filas que no tienen
+// las features del esquema (por ejemplo, de un modelo con otras features)
+// no se escriben; devuelve cuántas quedaron afuera para informarlo.
+func escribirCSVBronco(w io.Writer, features [][]float64, clases []int) (omitidas int, err error) {
+	schema := dataset.BroncoSchema()
+	cw := csv.NewWriter(w)
+	header := append(schema.FeatureNames(), schema.Label)
+	if err := cw.Write(header); err != nil {
+		return 0, err
+	}
+	for i, x := range features {
+		if len(x) != len(schema.Features) {
+			omitidas++
+			continue
+		}
+		record := make([]string, 0, len(header))
+		for _, v := range x {
+			record = append(record, strconv.FormatFloat(v, 'f', -1, 64))
+		}
+		record = append(record, strconv.Itoa(clases[i]))
+		if err := cw.Write(record); err != nil {
+			return omitidas, err
+		}
+	}
+	cw.Flush()
+	return omitidas, cw.Error()
+}
+
+// buscarDiagnostico devuelve el registro de auditoría de id, o nil.
+func buscarDiagnostico(id string) (*RegistroDiagnostico, error) {
+	var encontrado *RegistroDiagnostico
+	err := auditoria.recorrer(func(r RegistroDiagnostico) error {
+		if r.ID == id {
+			encontrado = &r
+		}
+		return nil
+	})
+	return encontrado, err
+}
+
+// ===== Handlers =====
+
+// parsearFecha acepta "2006-01-02" o RFC 3339; vacío es la fecha cero.
+func parsearFecha(valor string) (time.Time, error) {
+	if valor == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", valor); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, valor)
+}
+
+// exportarAuditoria devuelve los registros entre ?desde y ?hasta (hasta
+// excluido) como JSONL (?formato=jsonl, por defecto) o como CSV de
+// entrenamiento (?formato=csv). El CSV solo trae los diagnósticos que un
+// clínico corrigió, con la urgencia de la clase correcta como etiqueta: la
+// predicción del propio modelo no sirve para reentrenarlo.
+func exportarAuditoria(c *fiber.Ctx) error {
+	if auditoria.Path == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "El log de auditoria esta desactivado (UNMATCH_AUDITORIA=off)"})
+	}
+	desde, err := parsearFecha(c.Query("desde"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "desde: " + err.Error()})
+	}
+	hasta, err := parsearFecha(c.Query("hasta"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "hasta: " + err.Error()})
+	}
+	formato := c.Query("formato", "jsonl")
+	if formato != "jsonl" && formato != "csv" {
+		return c.Status(400).JSON(fiber.Map{"error": "formato debe ser jsonl o csv"})
+	}
+
+	var registros []RegistroDiagnostico
+	err = auditoria.recorrer(func(r RegistroDiagnostico) error {
+		if r.Fecha.Before(desde) || (!hasta.IsZero() && !r.Fecha.Before(hasta)) {
+			return nil
+		}
+		registros = append(registros, r)
+		return nil
+	})
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"diagnosticos.%s\"", formato))
+	if formato == "csv" {
+		muFeedback.Lock()
+		correcciones, err := LeerFeedback(rutaFeedback())
+		muFeedback.Unlock()
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		urgencias := make(map[string]int, len(correcciones))
+		for _, fb := range correcciones {
+			if etiqueta, err := fb.etiquetaModelo(); err == nil {
+				urgencias[fb.DiagnosticoID] = etiqueta
+			}
+		}
+		var features [][]float64
+		var clases []int
+		sinEtiqueta := 0
+		for _, r := range registros {
+			urgencia, ok := urgencias[r.ID]
+			if !ok {
+				sinEtiqueta++
+				continue
+			}
+			features = append(features, r.Features)
+			clases = append(clases, urgencia)
+		}
+		c.Set(fiber.HeaderContentType, "text/csv")
+		omitidas, err := escribirCSVBronco(c, features, clases)
+		if err != nil {
+			return err
+		}
+		// el cuerpo se envía al terminar el handler, así que los
+		// encabezados todavía se pueden agregar
+		c.Set("X-Sin-Etiqueta", strconv.Itoa(sinEtiqueta))
+		c.Set("X-Filas-Omitidas", strconv.Itoa(omitidas))
+		if omitidas > 0 {
+			fmt.Printf("Exportacion CSV: %d diagnosticos sin las features de bronco_dataset.csv omitidos\n", omitidas)
+		}
+		return nil
+	}
+	c.Set(fiber.HeaderContentType, "application/x-ndjson")
+	enc := json.NewEncoder(c)
+	for _, r := range registros {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// obtenerRegistroAuditoria devuelve el registro de un diagnóstico por id.
+func obtenerRegistroAuditoria(c *fiber.Ctx) error {
+	encontrado, err := buscarDiagnostico(c.Params("id"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if encontrado == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Diagnostico no encontrado"})
+	}
+	return c.JSON(encontrado)
+}
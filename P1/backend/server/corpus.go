@@ -0,0 +1,182 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// CasoCorpus es un texto de paciente etiquetado a mano. Es una línea JSON
+// del corpus; urgencia y enfermedad usan los valores de
+// dataset.BroncoClasses.
+type CasoCorpus struct {
+	Texto              string `json:"texto"`
+	Urgencia           string `json:"urgencia"`
+	Enfermedad         string `json:"enfermedad"`
+	RedflagPecho       bool   `json:"redflag_pecho"`
+	RedflagRespiracion bool   `json:"redflag_respiracion"`
+}
+
+// EtapaCorpus cuenta los aciertos de una etapa del pipeline.
+type EtapaCorpus struct {
+	Aciertos int     `json:"aciertos"`
+	Total    int     `json:"total"`
+	Accuracy float64 `json:"accuracy"`
+}
+
+func (e *EtapaCorpus) sumar(ok bool) {
+	e.Total++
+	if ok {
+		e.Aciertos++
+	}
+	e.Accuracy = float64(e.Aciertos) / float64(e.Total)
+}
+
+// FalloCorpus es un caso en el que una etapa no dio lo esperado.
+type FalloCorpus struct {
+	Linea    int    `json:"linea"`
+	Etapa    string `json:"etapa"`
+	Texto    string `json:"texto"`
+	Esperado string `json:"esperado"`
+	Obtenido string `json:"obtenido"`
+}
+
+// ReporteCorpus es el resultado de EvaluarCorpus. Cada etapa se compara
+// contra la etiqueta del caso aunque una etapa anterior haya fallado.
+// EnfermedadModelo solo se mide si el clasificador predice enfermedades
+// (una clase por fila de dataset.BroncoClasses); el modelo servido predice
+// la urgencia (dataset.UrgencyLevels) y la enfermedad sale del puntuador.
+type ReporteCorpus struct {
+	Casos              int           `json:"casos"`
+	Modelo             string        `json:"modelo"`
+	RedflagPecho       EtapaCorpus   `json:"redflag_pecho"`               // analizarTexto
+	RedflagRespiracion EtapaCorpus   `json:"redflag_respiracion"`         // analizarTexto
+	EnfermedadScorer   EtapaCorpus   `json:"enfermedad_scorer"`           // score a_* dominante
+	EnfermedadModelo   *EtapaCorpus  `json:"enfermedad_modelo,omitempty"` // clase del clasificador
+	UrgenciaModelo     EtapaCorpus   `json:"urgencia_modelo"`             // clase del clasificador
+	Fallos             []FalloCorpus `json:"fallos"`
+}
+
+// LeerCorpus lee un corpus JSONL y devuelve los casos con su número de línea.
+func LeerCorpus(path string) ([]CasoCorpus, []int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	var casos []CasoCorpus
+	var lineas []int
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		linea := strings.TrimSpace(sc.Text())
+		if linea == "" {
+			continue
+		}
+		var caso CasoCorpus
+		if err := json.Unmarshal([]byte(linea), &caso); err != nil {
+			return nil, nil, fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+		if caso.Texto == "" || caso.Urgencia == "" || caso.Enfermedad == "" {
+			return nil, nil, fmt.Errorf("%s:%d: texto, urgencia y enfermedad son requeridos", path, n)
+		}
+		casos = append(casos, caso)
+		lineas = append(lineas, n)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, nil, err
+	}
+	if len(casos) == 0 {
+		return nil, nil, fmt.Errorf("%s no tiene casos", path)
+	}
+	return casos, lineas, nil
+}
+
+// EvaluarCorpus pasa cada texto del corpus por el mismo pipeline que
+// /diagnostico (analizarTexto, puntuador de enfermedades y clasificador) y
+// mide el acierto de cada etapa. Con offline usa puntuarOffline en lugar de
+// HuggingFace.
+func EvaluarCorpus(path string, modelo algorithms.Classifier, offline bool) (*ReporteCorpus, error) {
+	casos, lineas, err := LeerCorpus(path)
+	if err != nil {
+		return nil, err
+	}
+	puntuar := puntuador(llamarHuggingFace)
+	if offline {
+		puntuar = puntuarOffline
+	}
+
+	reporte := &ReporteCorpus{Casos: len(casos), Modelo: modelo.ModelType()}
+	fallo := func(i int, etapa, esperado, obtenido string) {
+		reporte.Fallos = append(reporte.Fallos, FalloCorpus{
+			Linea:    lineas[i],
+			Etapa:    etapa,
+			Texto:    casos[i].Texto,
+			Esperado: esperado,
+			Obtenido: obtenido,
+		})
+	}
+
+	for i, caso := range casos {
+		featuresTexto := analizarTexto(caso.Texto)
+		reporte.RedflagPecho.sumar(featuresTexto.redflag_pecho == caso.RedflagPecho)
+		if featuresTexto.redflag_pecho != caso.RedflagPecho {
+			fallo(i, "redflag_pecho", fmt.Sprint(caso.RedflagPecho), fmt.Sprint(featuresTexto.redflag_pecho))
+		}
+		reporte.RedflagRespiracion.sumar(featuresTexto.redflag_respiracion == caso.RedflagRespiracion)
+		if featuresTexto.redflag_respiracion != caso.RedflagRespiracion {
+			fallo(i, "redflag_respiracion", fmt.Sprint(caso.RedflagRespiracion), fmt.Sprint(featuresTexto.redflag_respiracion))
+		}
+
+		probabilidades, err := puntuar(caso.Texto)
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %w", lineas[i], err)
+		}
+		_, Xdata := construirVector(featuresTexto, probabilidades)
+		scorer := dataset.BroncoClasses[dataset.DominantDisease(Xdata)].Disease
+		reporte.EnfermedadScorer.sumar(scorer == caso.Enfermedad)
+		if scorer != caso.Enfermedad {
+			fallo(i, "enfermedad_scorer", caso.Enfermedad, scorer)
+		}
+
+		X := mat.NewDense(1, len(Xdata), Xdata)
+		probs, err := modelo.PredictProba(X)
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %w", lineas[i], err)
+		}
+		pred, err := modelo.Predict(X)
+		if err != nil {
+			return nil, fmt.Errorf("línea %d: %w", lineas[i], err)
+		}
+		var urgencia string
+		switch _, nClases := probs.Dims(); nClases {
+		case len(dataset.UrgencyLevels):
+			urgencia = dataset.UrgencyLevels[pred[0]]
+		case len(dataset.BroncoClasses):
+			diagnostico := dataset.BroncoClasses[pred[0]]
+			urgencia = diagnostico.Urgency
+			if reporte.EnfermedadModelo == nil {
+				reporte.EnfermedadModelo = &EtapaCorpus{}
+			}
+			reporte.EnfermedadModelo.sumar(diagnostico.Disease == caso.Enfermedad)
+			if diagnostico.Disease != caso.Enfermedad {
+				fallo(i, "enfermedad_modelo", caso.Enfermedad, diagnostico.Disease)
+			}
+		default:
+			return nil, fmt.Errorf("el modelo tiene %d clases; se esperan %d urgencias o %d enfermedades",
+				nClases, len(dataset.UrgencyLevels), len(dataset.BroncoClasses))
+		}
+		reporte.UrgenciaModelo.sumar(urgencia == caso.Urgencia)
+		if urgencia != caso.Urgencia {
+			fallo(i, "urgencia_modelo", caso.Urgencia, urgencia)
+		}
+	}
+	return reporte, nil
+}
@@ -0,0 +1,277 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"unmatch/backend/dataset"
+)
+
+// RegistroFeedback es la corrección de un clínico sobre un diagnóstico del
+// log de auditoría. Guarda una copia de las features del diagnóstico, así
+// sigue sirviendo para reentrenar aunque el registro de auditoría se pode.
+//
+// Clase y ClasePredicha están en espacios distintos: el clínico elige una
+// enfermedad y el modelo predice una urgencia. Para comparar o reentrenar se
+// usa etiquetaModelo.
+type RegistroFeedback struct {
+	DiagnosticoID string    `json:"diagnostico_id"`
+	Fecha         time.Time `json:"fecha"`
+	Autor         string    `json:"autor,omitempty"`
+	Clase         int       `json:"clase"` // clase correcta, índice de dataset.BroncoClasses
+	Urgencia      string    `json:"urgencia"`
+	Enfermedad    string    `json:"enfermedad"`
+	Notas         string    `json:"notas,omitempty"`
+	ClasePredicha int       `json:"clase_predicha"` // salida del modelo, código de urgencia
+	Version       string    `json:"version,omitempty"`
+	Features      []float64 `json:"features"`
+}
+
+// FeedbackRequest es el cuerpo de POST /diagnostico/:id/feedback. La clase
+// correcta se da con clase o con enfermedad; urgencia es opcional y debe
+// coincidir con la de esa clase.
+type FeedbackRequest struct {
+	Clase      *int   `json:"clase"`
+	Enfermedad string `json:"enfermedad"`
+	Urgencia   string `json:"urgencia"`
+	Notas      string `json:"notas"`
+	Autor      string `json:"autor"`
+}
+
+// RutaFeedbackDefault es donde se guardan las correcciones si no se define
+// UNMATCH_FEEDBACK.
+const RutaFeedbackDefault = "./logs/feedback.jsonl"
+
+var errEtiquetaInvalida = errors.New("etiqueta invalida")
+
+// rutaFeedback devuelve el archivo de correcciones (UNMATCH_FEEDBACK).
+func rutaFeedback() string {
+	if path := os.Getenv("UNMATCH_FEEDBACK"); path != "" {
+		return path
+	}
+	return RutaFeedbackDefault
+}
+
+// muFeedback serializa las escrituras al archivo de correcciones.
+var muFeedback sync.Mutex
+
+// claseCorregida traduce la etiqueta de un clínico a la clase del modelo.
+// Cada clase es una enfermedad con una urgencia fija (dataset.BroncoClasses),
+// así que una urgencia distinta no se puede representar.
+func claseCorregida(req FeedbackRequest) (int, error) {
+	clase := -1
+	switch {
+	case req.Clase != nil:
+		if _, ok := dataset.BroncoClassOf(*req.Clase); !ok {
+			return 0, fmt.Errorf("clase %d no existe: %w", *req.Clase, errEtiquetaInvalida)
+		}
+		clase = *req.Clase
+	case req.Enfermedad != "":
+		for i, bc := range dataset.BroncoClasses {
+			if bc.Disease == req.Enfermedad {
+				clase = i
+			}
+		}
+		if clase < 0 {
+			return 0, fmt.Errorf("enfermedad %q no existe: %w", req.Enfermedad, errEtiquetaInvalida)
+		}
+	default:
+		return 0, fmt.Errorf("se requiere clase o enfermedad: %w", errEtiquetaInvalida)
+	}
+	if bc := dataset.BroncoClasses[clase]; req.Enfermedad != "" && req.Enfermedad != bc.Disease {
+		return 0, fmt.Errorf("la clase %d es %s, no %s: %w", clase, bc.Disease, req.Enfermedad, errEtiquetaInvalida)
+	}
+	if bc := dataset.BroncoClasses[clase]; req.Urgencia != "" && req.Urgencia != bc.Urgency {
+		return 0, fmt.Errorf("%s tiene urgencia %s en el modelo, no %s: %w", bc.Disease, bc.Urgency, req.Urgencia, errEtiquetaInvalida)
+	}
+	return clase, nil
+}
+
+// etiquetaModelo devuelve la clase corregida en el espacio de etiquetas del
+// modelo: el código de urgencia de la columna urgencia de
+// bronco_dataset.csv (dataset.UrgencyLevels).
+func (r RegistroFeedback) etiquetaModelo() (int, error) {
+	bc, ok := dataset.BroncoClassOf(r.Clase)
+	if !ok {
+		return 0, fmt.Errorf("correccion de %s: clase %d no existe: %w", r.DiagnosticoID, r.Clase, errEtiquetaInvalida)
+	}
+	return bc.UrgencyCode(), nil
+}
+
+// LeerFeedback devuelve la última corrección de cada diagnóstico del
+// archivo path, en el orden en que se corrigió cada uno por primera vez.
+func LeerFeedback(path string) ([]RegistroFeedback, error) {
+	var registros []RegistroFeedback
+	posicion := map[string]int{}
+	err := leerJSONL(path, func(linea []byte) error {
+		var r RegistroFeedback
+		if err := json.Unmarshal(linea, &r); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if i, ok := posicion[r.DiagnosticoID]; ok {
+			registros[i] = r
+			return nil
+		}
+		posicion[r.DiagnosticoID] = len(registros)
+		registros = append(registros, r)
+		return nil
+	})
+	return registros, err
+}
+
+// ExportarFeedback escribe en salida un CSV con el esquema de
+// bronco_dataset.csv y las urgencias de las clases corregidas por los
+// clínicos (etiquetaModelo). Si base no es vacío, sus filas van primero, así
+// el archivo sirve directamente para reentrenar; sus etiquetas deben ser
+// códigos de urgencia. Devuelve cuántas correcciones exportó y cuántas omitió por
+// no tener las features de bronco_dataset.csv.
+func ExportarFeedback(path, base, salida string) (exportadas, omitidas int, err error) {
+	registros, err := LeerFeedback(path)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var features [][]float64
+	var clases []int
+	if base != "" {
+		ds, err := dataset.Load(base, dataset.BroncoSchema())
+		if err != nil {
+			return 0, 0, err
+		}
+		for i, y := range ds.Y {
+			if y >= len(dataset.UrgencyLevels) {
+				return 0, 0, fmt.Errorf("%s: fila %d: %d no es un codigo de urgencia: %w", base, i+1, y, errEtiquetaInvalida)
+			}
+			features = append(features, ds.X.RawRowView(i))
+		}
+		clases = append(clases, ds.Y...)
+	}
+	for _, r := range registros {
+		etiqueta, err := r.etiquetaModelo()
+		if err != nil {
+			return 0, 0, err
+		}
+		features = append(features, r.Features)
+		clases = append(clases, etiqueta)
+	}
+
+	f, err := os.Create(salida)
+	if err != nil {
+		return 0, 0, err
+	}
+	// las filas de base tienen el esquema, solo se omiten correcciones
+	omitidas, err = escribirCSVBronco(f, features, clases)
+	if err != nil {
+		f.Close()
+		return 0, 0, err
+	}
+	return len(registros) - omitidas, omitidas, f.Close()
+}
+
+// guardarFeedback agrega una corrección al archivo de correcciones.
+func guardarFeedback(r RegistroFeedback) error {
+	muFeedback.Lock()
+	defer muFeedback.Unlock()
+	return agregarJSONL(rutaFeedback(), r)
+}
+
+// ===== Handlers =====
+
+// soloClinico protege las rutas de los clínicos: acepta el token de
+// UNMATCH_CLINICO_TOKEN o el de admin, en "Authorization: Bearer <token>" o
+// en X-Clinico-Token.
+func soloClinico(c *fiber.Ctx) error {
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if token == "" {
+		token = c.Get("X-Clinico-Token")
+	}
+	for _, esperado := range []string{os.Getenv("UNMATCH_CLINICO_TOKEN"), os.Getenv("UNMATCH_ADMIN_TOKEN")} {
+		if esperado != "" && subtle.ConstantTimeCompare([]byte(token), []byte(esperado)) == 1 {
+			return c.Next()
+		}
+	}
+	return c.Status(fiber.StatusForbidden).JSON(fiber.Map{"error": "se requiere token de clinico"})
+}
+
+// registrarFeedback guarda la clase correcta de un diagnóstico registrado.
+// Se puede corregir varias veces; vale la última.
+func registrarFeedback(c *fiber.Ctx) error {
+	var req FeedbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Error al parsear JSON de entrada", "detalle": err.Error()})
+	}
+	clase, err := claseCorregida(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if auditoria.Path == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "El log de auditoria esta desactivado (UNMATCH_AUDITORIA=off)"})
+	}
+
+	id := utils.CopyString(c.Params("id"))
+	diagnostico, err := buscarDiagnostico(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if diagnostico == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Diagnostico no encontrado"})
+	}
+
+	bc := dataset.BroncoClasses[clase]
+	registro := RegistroFeedback{
+		DiagnosticoID: id,
+		Fecha:         time.Now().UTC(),
+		Autor:         req.Autor,
+		Clase:         clase,
+		Urgencia:      bc.Urgency,
+		Enfermedad:    bc.Disease,
+		Notas:         req.Notas,
+		ClasePredicha: diagnostico.Clase,
+		Version:       diagnostico.Version,
+		Features:      diagnostico.Features,
+	}
+	if err := guardarFeedback(registro); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	// si el diagnóstico estaba en la cola de revisión, ya tiene etiqueta
+	_, err = revision.marcarEtiquetado(registro)
+	if err != nil && !errors.Is(err, errCasoNoEncontrado) && !errors.Is(err, errCasoCerrado) {
+		fmt.Printf("No se pudo actualizar la cola de revision para %s: %v\n", id, err)
+	}
+	fmt.Printf("Feedback de %s: clase %d, urgencia %d (predicha %d)\n", id, clase, bc.UrgencyCode(), diagnostico.Clase)
+	return c.Status(201).JSON(fiber.Map{
+		"mensaje":   "Feedback registrado",
+		"feedback":  registro,
+		"corregido": bc.UrgencyCode() != diagnostico.Clase,
+	})
+}
+
+// listarFeedback devuelve la última corrección de cada diagnóstico.
+func listarFeedback(c *fiber.Ctx) error {
+	muFeedback.Lock()
+	registros, err := LeerFeedback(rutaFeedback())
+	muFeedback.Unlock()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	corregidos := 0
+	for _, r := range registros {
+		if etiqueta, err := r.etiquetaModelo(); err == nil && etiqueta != r.ClasePredicha {
+			corregidos++
+		}
+	}
+	return c.JSON(fiber.Map{
+		"total":      len(registros),
+		"corregidos": corregidos,
+		"feedback":   registros,
+	})
+}
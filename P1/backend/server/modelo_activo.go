@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"unmatch/backend/algorithms"
+)
+
+// modeloServido es el modelo que responde /diagnostico y /softmax/predict.
+// No se modifica una vez publicado: cambiar el modelo es publicar otro. Solo
+// Drift acumula estado, con su propio mutex.
+type modeloServido struct {
+	Modelo  algorithms.Classifier
+	Version string // "" si no vino del registro
+	Origen  string // "registro", "entrenamiento" o la ruta del archivo
+	Cargado time.Time
+	Drift   *monitorDrift // nil si el modelo no tiene estadísticas de entrenamiento
+}
+
+// contenedorModelo guarda el modelo servido. Los handlers leen con obtener
+// sin bloquearse (atomic.Pointer); los cambios (entrenamiento, promoción,
+// recarga, vigilancia del archivo) se serializan con mu para que el modelo
+// publicado coincida con la versión activa del registro.
+type contenedorModelo struct {
+	actual atomic.Pointer[modeloServido]
+	mu     sync.Mutex
+}
+
+var modeloActivo = &contenedorModelo{}
+
+// obtener devuelve el modelo servido, o nil si todavía no hay ninguno.
+func (c *contenedorModelo) obtener() *modeloServido {
+	return c.actual.Load()
+}
+
+// publicar reemplaza el modelo servido; se llama con c.mu tomado.
+func (c *contenedorModelo) publicar(modelo algorithms.Classifier, version, origen string) *modeloServido {
+	m := &modeloServido{
+		Modelo:  modelo,
+		Version: version,
+		Origen:  origen,
+		Cargado: time.Now().UTC(),
+		Drift:   nuevoMonitorDrift(modelo),
+	}
+	c.actual.Store(m)
+	return m
+}
+
+// obtenerOCargar devuelve el modelo servido y, si no hay, lo carga con
+// cargarModeloActivo. Varias peticiones simultáneas cargan una sola vez.
+func (c *contenedorModelo) obtenerOCargar() (*modeloServido, error) {
+	if m := c.obtener(); m != nil {
+		return m, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if m := c.obtener(); m != nil {
+		return m, nil
+	}
+	return c.cargar()
+}
+
+// recargar vuelve a leer la versión activa del disco, por ejemplo después de
+// un `unmatch models promote` en la misma máquina.
+func (c *contenedorModelo) recargar() (*modeloServido, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.cargar()
+}
+
+func (c *contenedorModelo) cargar() (*modeloServido, error) {
+	modelo, id, err := cargarModeloActivo()
+	if err != nil {
+		return nil, err
+	}
+	origen := "registro"
+	if id == "" {
+		origen = softmaxModelPath
+	}
+	return c.publicar(modelo, id, origen), nil
+}
+
+// activar cambia la versión activa del registro con cambiar y publica
+// modelo, como una sola operación: si cambiar falla el modelo servido no
+// cambia.
+func (c *contenedorModelo) activar(modelo algorithms.Classifier, id, origen string, cambiar func() error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := cambiar(); err != nil {
+		return err
+	}
+	c.publicar(modelo, id, origen)
+	return nil
+}
+
+// vigilar revisa cada intervalo si cambió el archivo path (fecha o tamaño) y
+// en ese caso lo carga y lo publica. Si el archivo no carga (por ejemplo,
+// copiado a medias) se deja el modelo actual y se reintenta en la siguiente
+// revisión. Termina al cerrarse fin (nil: nunca); se llama en una
+// goroutine.
+func (c *contenedorModelo) vigilar(path string, intervalo time.Duration, fin <-chan struct{}) {
+	var ultimo os.FileInfo
+	if info, err := os.Stat(path); err == nil {
+		ultimo = info
+	}
+	ticker := time.NewTicker(intervalo)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-fin:
+			return
+		case <-ticker.C:
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if ultimo != nil && info.ModTime().Equal(ultimo.ModTime()) && info.Size() == ultimo.Size() {
+			continue
+		}
+		if err := c.cargarArchivo(path); err != nil {
+			fmt.Printf("No se pudo recargar %s: %v\n", path, err)
+			continue
+		}
+		ultimo = info
+	}
+}
+
+// cargarArchivo publica el modelo de path, verificado igual que el
+// registro. La versión es la activa del registro, que es la que se copia a
+// softmaxModelPath al promover.
+func (c *contenedorModelo) cargarArchivo(path string) error {
+	modelo, err := algorithms.LoadVerifiedClassifier(path, registroModelos.VerifyKey)
+	if err != nil {
+		return err
+	}
+	id, _ := registroModelos.Active()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.publicar(modelo, id, path)
+	fmt.Printf("Modelo %s recargado desde %s\n", modelo.ModelType(), path)
+	return nil
+}
+
+// intervaloVigilancia lee UNMATCH_WATCH_MODELO ("5s", "1m"...): cada cuánto
+// revisar softmaxModelPath. Vacío desactiva la vigilancia.
+func intervaloVigilancia() (time.Duration, error) {
+	valor := os.Getenv("UNMATCH_WATCH_MODELO")
+	if valor == "" {
+		return 0, nil
+	}
+	intervalo, err := time.ParseDuration(valor)
+	if err != nil || intervalo <= 0 {
+		return 0, fmt.Errorf("UNMATCH_WATCH_MODELO=%q no es una duracion valida", valor)
+	}
+	return intervalo, nil
+}
@@ -0,0 +1,215 @@
+package server
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+)
+
+// Correr con -race: las pruebas cambian el modelo servido mientras otras
+// goroutines lo leen.
+
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "unmatch-registro")
+	if err != nil {
+		panic(err)
+	}
+	registroModelos = algorithms.NewRegistry(dir)
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+var xPrueba = mat.NewDense(2, 2, []float64{0.1, 0.9, 0.8, 0.2})
+
+// entrenarModelo devuelve un modelo softmax de nClases clases.
+func entrenarModelo(t *testing.T, nClases int) (*algorithms.SoftmaxRegression, *mat.Dense, []int) {
+	t.Helper()
+	n := 6 * nClases
+	X := mat.NewDense(n, 2, nil)
+	y := make([]int, n)
+	for i := range y {
+		y[i] = i % nClases
+		X.Set(i, 0, float64(y[i]))
+		X.Set(i, 1, float64(i%3))
+	}
+	m := algorithms.NewSoftmaxRegression(0.1, 50, 1e-3)
+	if err := m.Fit(X, y); err != nil {
+		t.Fatal(err)
+	}
+	return m, X, y
+}
+
+// clasesServidas devuelve el número de clases del modelo servido, o 0 si no
+// hay ninguno.
+func clasesServidas(t *testing.T, c *contenedorModelo) int {
+	s := c.obtener()
+	if s == nil {
+		return 0
+	}
+	probs, err := s.Modelo.PredictProba(xPrueba)
+	if err != nil {
+		t.Error(err)
+		return 0
+	}
+	_, k := probs.Dims()
+	return k
+}
+
+func TestContenedorModeloConcurrente(t *testing.T) {
+	modelos := map[string]algorithms.Classifier{}
+	clases := map[string]int{}
+	var ids []string
+	for _, k := range []int{2, 3} {
+		m, X, y := entrenarModelo(t, k)
+		v, err := registroModelos.Register(m, algorithms.NewTrainingManifest(m, X, y, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		modelos[v.ID] = m
+		clases[v.ID] = k
+		ids = append(ids, v.ID)
+	}
+	if err := registroModelos.Promote(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &contenedorModelo{}
+	fin := make(chan struct{})
+	var lectores sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		lectores.Add(1)
+		go func() {
+			defer lectores.Done()
+			for {
+				select {
+				case <-fin:
+					return
+				default:
+				}
+				s, err := c.obtenerOCargar()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if _, ok := modelos[s.Version]; !ok {
+					t.Errorf("version servida %q no es del registro", s.Version)
+					return
+				}
+				if _, err := s.Modelo.PredictProba(xPrueba); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	var escritores sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		escritores.Add(1)
+		go func(i int) {
+			defer escritores.Done()
+			for j := 0; j < 20; j++ {
+				id := ids[(i+j)%len(ids)]
+				var err error
+				if i%2 == 0 {
+					err = c.activar(modelos[id], id, "prueba", func() error {
+						return registroModelos.Promote(id)
+					})
+				} else {
+					_, err = c.recargar()
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(i)
+	}
+	escritores.Wait()
+	close(fin)
+	lectores.Wait()
+
+	// el modelo servido es siempre el de la versión activa del registro
+	activa, err := registroModelos.Active()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := c.obtener(); s.Version != activa {
+		t.Errorf("se sirve la version %q y la activa es %q", s.Version, activa)
+	}
+	if k := clasesServidas(t, c); k != clases[activa] {
+		t.Errorf("el modelo servido tiene %d clases y el de la version activa %d", k, clases[activa])
+	}
+}
+
+func TestVigilarRecargaArchivo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "softmax_model.json")
+	dos, _, _ := entrenarModelo(t, 2)
+	tres, _, _ := entrenarModelo(t, 3)
+	if err := dos.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &contenedorModelo{}
+	fin := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.vigilar(path, 5*time.Millisecond, fin)
+	}()
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-fin:
+				return
+			default:
+				clasesServidas(t, c)
+			}
+		}
+	}()
+	defer func() {
+		close(fin)
+		wg.Wait()
+	}()
+
+	esperarClases := func(k int) {
+		t.Helper()
+		limite := time.Now().Add(2 * time.Second)
+		for clasesServidas(t, c) != k {
+			if time.Now().After(limite) {
+				t.Fatalf("el modelo servido no paso a %d clases", k)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// vigilar toma la fecha y el tamaño iniciales del archivo al arrancar
+	time.Sleep(50 * time.Millisecond)
+	if err := tres.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	esperarClases(3)
+
+	// un archivo a medio copiar no reemplaza al modelo servido
+	if err := os.WriteFile(path, []byte(`{"model_type": "softmax", "W": [`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if k := clasesServidas(t, c); k != 3 {
+		t.Fatalf("con el archivo roto se sirve un modelo de %d clases", k)
+	}
+
+	if err := dos.SaveToFile(path); err != nil {
+		t.Fatal(err)
+	}
+	esperarClases(2)
+}
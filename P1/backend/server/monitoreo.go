@@ -0,0 +1,106 @@
+package server
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	"gonum.org/v1/gonum/mat"
+
+	"unmatch/backend/algorithms"
+)
+
+// monitorDrift compara las entradas que recibe el modelo servido con las
+// estadísticas de su conjunto de entrenamiento. Cada modelo publicado tiene
+// el suyo, así que al cambiar de modelo las cuentas empiezan de cero.
+type monitorDrift struct {
+	*algorithms.DriftMonitor
+
+	mu    sync.Mutex
+	nivel string // último nivel reportado, para avisar solo cuando cambia
+}
+
+// nuevoMonitorDrift devuelve nil si el modelo no guarda estadísticas de
+// entrenamiento (artefactos anteriores al monitoreo).
+func nuevoMonitorDrift(modelo algorithms.Classifier) *monitorDrift {
+	sc, ok := modelo.(algorithms.StatsCarrier)
+	if !ok || sc.TrainingStats() == nil {
+		return nil
+	}
+	return &monitorDrift{
+		DriftMonitor: algorithms.NewDriftMonitor(sc.TrainingStats()),
+		nivel:        algorithms.DriftOK,
+	}
+}
+
+// observar registra una predicción servida. Los errores solo se imprimen:
+// el monitoreo no debe hacer fallar una predicción.
+func (m *monitorDrift) observar(X *mat.Dense, clases []int) {
+	if m == nil {
+		return
+	}
+	if err := m.Observe(X, clases); err != nil {
+		fmt.Println("  Monitoreo de drift:", err)
+		return
+	}
+
+	reporte := m.reporte()
+	m.mu.Lock()
+	cambio := reporte.Level != m.nivel
+	m.nivel = reporte.Level
+	m.mu.Unlock()
+	if cambio {
+		fmt.Printf("  DRIFT: nivel %s tras %d predicciones\n", reporte.Level, reporte.NObserved)
+		for _, alerta := range reporte.Alerts {
+			fmt.Println("   -", alerta)
+		}
+	}
+}
+
+// reporte es Report con los umbrales por defecto y con los nombres de
+// nombresFeatures en las columnas que no tienen nombre en el artefacto.
+func (m *monitorDrift) reporte() algorithms.DriftReport {
+	reporte := m.Report(algorithms.DefaultDriftThresholds())
+	if len(reporte.Features) == len(nombresFeatures) {
+		for i := range reporte.Features {
+			if reporte.Features[i].Feature == "" {
+				reporte.Features[i].Feature = nombresFeatures[i]
+			}
+		}
+	}
+	return reporte
+}
+
+// ===== Handlers =====
+
+func reporteDrift(c *fiber.Ctx) error {
+	servido := modeloActivo.obtener()
+	if servido == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Modelo no entrenado. Primero llame a /softmax/train",
+		})
+	}
+	if servido.Drift == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("El modelo activo (%s) no tiene estadisticas de entrenamiento; vuelva a entrenarlo para monitorear drift", servido.Modelo.ModelType()),
+		})
+	}
+	return c.JSON(fiber.Map{
+		"modelo":  servido.Modelo.ModelType(),
+		"version": servido.Version,
+		"desde":   servido.Cargado,
+		"reporte": servido.Drift.reporte(),
+	})
+}
+
+func reiniciarDrift(c *fiber.Ctx) error {
+	servido := modeloActivo.obtener()
+	if servido == nil || servido.Drift == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "No hay monitoreo de drift activo"})
+	}
+	servido.Drift.Reset()
+	servido.Drift.mu.Lock()
+	servido.Drift.nivel = algorithms.DriftOK
+	servido.Drift.mu.Unlock()
+	return c.JSON(fiber.Map{"mensaje": "Estadisticas de drift reiniciadas", "version": servido.Version})
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"math"
+	"os"
+	"strings"
+)
+
+// puntuador devuelve el score de cada enfermedad para un texto, con las
+// mismas claves que HuggingFace ("asma", "bronquitis", ..., "migrañas").
+type puntuador func(texto string) (map[string]float64, error)
+
+// puntuadorEnfermedades es el puntuador de /diagnostico. Con la variable de
+// entorno UNMATCH_SCORER=offline se usa puntuarOffline en lugar de
+// HuggingFace (sin red ni HF_TOKEN).
+func puntuadorEnfermedades() puntuador {
+	if os.Getenv("UNMATCH_SCORER") == "offline" {
+		return puntuarOffline
+	}
+	return llamarHuggingFace
+}
+
+// enfermedadesKeywords son las palabras que puntuarOffline asocia a cada
+// enfermedad. La primera es el nombre de la enfermedad.
+var enfermedadesKeywords = map[string][]string{
+	"asma":         {"asma", "silbido", "sibilancias", "inhalador", "salbutamol"},
+	"bronquitis":   {"bronquitis", "flema", "esputo", "mucosidad", "tos productiva"},
+	"enfisema":     {"enfisema", "epoc", "fumador", "fumo", "oxigeno en casa"},
+	"apnea":        {"apnea", "ronco", "ronquidos", "dejo de respirar dormido", "somnolencia"},
+	"fibromialgia": {"fibromialgia", "dolor muscular", "dolor en todo el cuerpo", "puntos dolorosos"},
+	"migrañas":     {"migraña", "migrana", "dolor de cabeza", "jaqueca", "luz me molesta"},
+	"reflujo":      {"reflujo", "acidez", "agruras", "ardor", "regurgitacion"},
+}
+
+// puntuarOffline es un puntuador por palabras clave: nombrar la enfermedad
+// da 0.8 y cada palabra asociada suma 0.3, con un máximo de 0.95. Es más
+// pobre que HuggingFace pero determinista, por eso lo usan las pruebas de
+// corpus.
+func puntuarOffline(texto string) (map[string]float64, error) {
+	textoLower := strings.ToLower(texto)
+	scores := make(map[string]float64, len(enfermedadesKeywords))
+	for enfermedad, keywords := range enfermedadesKeywords {
+		score := 0.05
+		for i, kw := range keywords {
+			if !strings.Contains(textoLower, kw) {
+				continue
+			}
+			if i == 0 {
+				score += 0.8
+			} else {
+				score += 0.3
+			}
+		}
+		scores[enfermedad] = math.Min(score, 0.95)
+	}
+	return scores, nil
+}
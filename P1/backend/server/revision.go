@@ -0,0 +1,340 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"unmatch/backend/algorithms"
+	"unmatch/backend/dataset"
+)
+
+// Estados de un caso de la cola de revisión.
+const (
+	estadoPendiente  = "pendiente"  // espera la etiqueta de un clínico
+	estadoEtiquetado = "etiquetado" // tiene etiqueta (en feedback.jsonl), falta cerrarlo
+	estadoCerrado    = "cerrado"    // revisado; ya no se puede etiquetar
+)
+
+// CasoRevision es un diagnóstico en el que el modelo tuvo poca confianza,
+// guardado para que lo etiquete un clínico. Enfermedad y Urgencia son las
+// predichas; la etiqueta del clínico va en Etiqueta.
+type CasoRevision struct {
+	ID             string                 `json:"id"` // id del diagnóstico
+	Fecha          time.Time              `json:"fecha"`
+	Estado         string                 `json:"estado"`
+	Prioridad      float64                `json:"prioridad"`
+	Incertidumbre  algorithms.Uncertainty `json:"incertidumbre"`
+	Modelo         string                 `json:"modelo"`
+	Version        string                 `json:"version,omitempty"`
+	Clase          int                    `json:"clase"`
+	Enfermedad     string                 `json:"enfermedad"`
+	Urgencia       string                 `json:"urgencia"`
+	Probabilidades []float64              `json:"probabilidades"`
+	Features       []float64              `json:"features"`
+	Texto          string                 `json:"texto,omitempty"`
+	TextoModo      string                 `json:"texto_modo"`
+	Etiqueta       *int                   `json:"etiqueta,omitempty"`
+	Autor          string                 `json:"autor,omitempty"`
+	Notas          string                 `json:"notas,omitempty"`
+	Actualizado    time.Time              `json:"actualizado"`
+}
+
+// CierreRequest es el cuerpo (opcional) de POST /revision/:id/cerrar.
+type CierreRequest struct {
+	Autor string `json:"autor"`
+	Notas string `json:"notas"`
+}
+
+const rutaRevisionDefault = "./logs/revision.jsonl"
+
+var (
+	errCasoNoEncontrado = errors.New("caso no encontrado en la cola de revision")
+	errCasoCerrado      = errors.New("el caso ya esta cerrado")
+)
+
+// colaRevision guarda los casos inciertos en memoria y cada cambio como una
+// línea del archivo Path; al arrancar vale la última línea de cada caso.
+type colaRevision struct {
+	Path     string // "" desactiva la cola
+	Umbrales algorithms.UncertaintyThresholds
+	mu       sync.Mutex
+	casos    map[string]*CasoRevision
+}
+
+var revision = &colaRevision{}
+
+// configurarRevision lee la configuración de la cola de las variables de
+// entorno y carga los casos guardados:
+//
+//   - UNMATCH_REVISION: ruta del archivo (por defecto ./logs/revision.jsonl);
+//     "off" desactiva la cola.
+//   - UNMATCH_REVISION_ENTROPIA: entropía normalizada a partir de la cual un
+//     diagnóstico va a revisión (por defecto 0.8).
+//   - UNMATCH_REVISION_MARGEN: diferencia mínima entre las dos clases más
+//     probables; por debajo va a revisión (por defecto 0.2).
+func configurarRevision() (*colaRevision, error) {
+	q := &colaRevision{
+		Path:     os.Getenv("UNMATCH_REVISION"),
+		Umbrales: algorithms.DefaultUncertaintyThresholds(),
+		casos:    map[string]*CasoRevision{},
+	}
+	switch q.Path {
+	case "":
+		q.Path = rutaRevisionDefault
+	case "off":
+		q.Path = ""
+		return q, nil
+	}
+	for variable, destino := range map[string]*float64{
+		"UNMATCH_REVISION_ENTROPIA": &q.Umbrales.MaxEntropy,
+		"UNMATCH_REVISION_MARGEN":   &q.Umbrales.MinMargin,
+	} {
+		if valor := os.Getenv(variable); valor != "" {
+			v, err := strconv.ParseFloat(valor, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s=%q no es un numero", variable, valor)
+			}
+			*destino = v
+		}
+	}
+	if err := q.Umbrales.Validate(); err != nil {
+		return nil, fmt.Errorf("UNMATCH_REVISION_ENTROPIA / UNMATCH_REVISION_MARGEN: %w", err)
+	}
+
+	err := leerJSONL(q.Path, func(linea []byte) error {
+		var caso CasoRevision
+		if err := json.Unmarshal(linea, &caso); err != nil {
+			return fmt.Errorf("%s: %w", q.Path, err)
+		}
+		q.casos[caso.ID] = &caso
+		return nil
+	})
+	return q, err
+}
+
+// requiereRevision indica si una predicción con incertidumbre u va a la cola.
+func (q *colaRevision) requiereRevision(u algorithms.Uncertainty) bool {
+	return q.Path != "" && q.Umbrales.Uncertain(u)
+}
+
+// guardar escribe caso en el archivo y lo deja en memoria; se llama con
+// q.mu tomado.
+func (q *colaRevision) guardar(caso CasoRevision) error {
+	caso.Actualizado = time.Now().UTC()
+	if err := agregarJSONL(q.Path, caso); err != nil {
+		return err
+	}
+	q.casos[caso.ID] = &caso
+	return nil
+}
+
+// encolar agrega un diagnóstico incierto como caso pendiente.
+func (q *colaRevision) encolar(caso CasoRevision) error {
+	if q.Path == "" {
+		return nil
+	}
+	caso.Estado = estadoPendiente
+	caso.Prioridad = caso.Incertidumbre.Priority()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.guardar(caso)
+}
+
+// obtener devuelve una copia del caso id.
+func (q *colaRevision) obtener(id string) (CasoRevision, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	caso, ok := q.casos[id]
+	if !ok {
+		return CasoRevision{}, false
+	}
+	return *caso, true
+}
+
+// listar devuelve los casos en estado (todos si es ""), los más inciertos
+// primero y, a igual prioridad, los más viejos. limite <= 0 no limita.
+func (q *colaRevision) listar(estado string, limite int) []CasoRevision {
+	q.mu.Lock()
+	casos := make([]CasoRevision, 0, len(q.casos))
+	for _, caso := range q.casos {
+		if estado == "" || caso.Estado == estado {
+			casos = append(casos, *caso)
+		}
+	}
+	q.mu.Unlock()
+	sort.Slice(casos, func(i, j int) bool {
+		if casos[i].Prioridad != casos[j].Prioridad {
+			return casos[i].Prioridad > casos[j].Prioridad
+		}
+		return casos[i].Fecha.Before(casos[j].Fecha)
+	})
+	if limite > 0 && len(casos) > limite {
+		casos = casos[:limite]
+	}
+	return casos
+}
+
+// marcarEtiquetado pasa el caso de r.DiagnosticoID a etiquetado con la clase
+// de r. Un caso etiquetado se puede volver a etiquetar; uno cerrado no.
+func (q *colaRevision) marcarEtiquetado(r RegistroFeedback) (CasoRevision, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	actual, ok := q.casos[r.DiagnosticoID]
+	if !ok {
+		return CasoRevision{}, errCasoNoEncontrado
+	}
+	if actual.Estado == estadoCerrado {
+		return *actual, errCasoCerrado
+	}
+	caso := *actual
+	clase := r.Clase
+	caso.Estado = estadoEtiquetado
+	caso.Etiqueta = &clase
+	caso.Autor = r.Autor
+	caso.Notas = r.Notas
+	return caso, q.guardar(caso)
+}
+
+// cerrar saca el caso id de la cola, tenga etiqueta o no (un clínico puede
+// descartarlo sin etiquetar).
+func (q *colaRevision) cerrar(id string, req CierreRequest) (CasoRevision, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	actual, ok := q.casos[id]
+	if !ok {
+		return CasoRevision{}, errCasoNoEncontrado
+	}
+	if actual.Estado == estadoCerrado {
+		return *actual, errCasoCerrado
+	}
+	caso := *actual
+	caso.Estado = estadoCerrado
+	if req.Autor != "" {
+		caso.Autor = req.Autor
+	}
+	if req.Notas != "" {
+		caso.Notas = req.Notas
+	}
+	return caso, q.guardar(caso)
+}
+
+// ===== Handlers =====
+
+// statusRevision traduce un error de la cola a un código HTTP.
+func statusRevision(err error) int {
+	switch {
+	case errors.Is(err, errCasoNoEncontrado):
+		return fiber.StatusNotFound
+	case errors.Is(err, errCasoCerrado):
+		return fiber.StatusConflict
+	}
+	return fiber.StatusInternalServerError
+}
+
+// listarRevision devuelve la cola ordenada por prioridad. ?estado filtra
+// (pendiente por defecto; "todos" no filtra) y ?limite acota la cantidad.
+func listarRevision(c *fiber.Ctx) error {
+	if revision.Path == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "La cola de revision esta desactivada (UNMATCH_REVISION=off)"})
+	}
+	estado := c.Query("estado", estadoPendiente)
+	switch estado {
+	case estadoPendiente, estadoEtiquetado, estadoCerrado:
+	case "todos":
+		estado = ""
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "estado debe ser pendiente, etiquetado, cerrado o todos"})
+	}
+	limite := c.QueryInt("limite", 0)
+	if limite < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "limite debe ser positivo"})
+	}
+	casos := revision.listar(estado, limite)
+	return c.JSON(fiber.Map{
+		"total":    len(casos),
+		"umbrales": revision.Umbrales,
+		"casos":    casos,
+	})
+}
+
+// obtenerCasoRevision devuelve un caso de la cola.
+func obtenerCasoRevision(c *fiber.Ctx) error {
+	caso, ok := revision.obtener(c.Params("id"))
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": errCasoNoEncontrado.Error()})
+	}
+	return c.JSON(caso)
+}
+
+// etiquetarCaso registra la clase correcta de un caso de la cola. La
+// etiqueta se guarda también como feedback, así entra en `unmatch feedback`
+// y en /modelos/actualizar.
+func etiquetarCaso(c *fiber.Ctx) error {
+	var req FeedbackRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Error al parsear JSON de entrada", "detalle": err.Error()})
+	}
+	clase, err := claseCorregida(req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	id := utils.CopyString(c.Params("id"))
+	caso, ok := revision.obtener(id)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": errCasoNoEncontrado.Error()})
+	}
+	if caso.Estado == estadoCerrado {
+		return c.Status(409).JSON(fiber.Map{"error": errCasoCerrado.Error()})
+	}
+
+	bc := dataset.BroncoClasses[clase]
+	registro := RegistroFeedback{
+		DiagnosticoID: id,
+		Fecha:         time.Now().UTC(),
+		Autor:         req.Autor,
+		Clase:         clase,
+		Urgencia:      bc.Urgency,
+		Enfermedad:    bc.Disease,
+		Notas:         req.Notas,
+		ClasePredicha: caso.Clase,
+		Version:       caso.Version,
+		Features:      caso.Features,
+	}
+	if err := guardarFeedback(registro); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	caso, err = revision.marcarEtiquetado(registro)
+	if err != nil {
+		return c.Status(statusRevision(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	fmt.Printf("Caso en revision %s etiquetado: clase %d, urgencia %d (predicha %d)\n", id, clase, bc.UrgencyCode(), caso.Clase)
+	return c.JSON(fiber.Map{
+		"mensaje":   "Caso etiquetado",
+		"caso":      caso,
+		"feedback":  registro,
+		"corregido": bc.UrgencyCode() != caso.Clase,
+	})
+}
+
+// cerrarCaso cierra un caso de la cola, con o sin etiqueta.
+func cerrarCaso(c *fiber.Ctx) error {
+	var req CierreRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Error al parsear JSON de entrada", "detalle": err.Error()})
+	}
+	caso, err := revision.cerrar(utils.CopyString(c.Params("id")), req)
+	if err != nil {
+		return c.Status(statusRevision(err)).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{"mensaje": "Caso cerrado", "caso": caso})
+}
@@ -0,0 +1,355 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/utils"
+
+	"unmatch/backend/algorithms"
+)
+
+// Estados de un trabajo de entrenamiento.
+const (
+	TrabajoPendiente  = "pendiente"
+	TrabajoEntrenando = "entrenando"
+	TrabajoTerminado  = "terminado"
+	TrabajoFallido    = "fallido"
+	TrabajoCancelado  = "cancelado"
+)
+
+// Trabajo es un entrenamiento que corre en segundo plano. Iteracion, NIter y
+// Loss se actualizan en cada punto de control de los modelos iterativos
+// (softmax, mlp), que también se agrega a Curva con las métricas de
+// validación; Version es la versión del registro que produjo al terminar.
+type Trabajo struct {
+	ID        string                `json:"id"`
+	Modelo    string                `json:"modelo"`
+	Estado    string                `json:"estado"`
+	Iteracion int                   `json:"iteracion"`
+	NIter     int                   `json:"n_iter"`
+	Loss      float64               `json:"loss"`
+	Curva     []algorithms.Progress `json:"curva,omitempty"`
+	Version   string                `json:"version,omitempty"`
+	Resultado fiber.Map             `json:"resultado,omitempty"`
+	Error     string                `json:"error,omitempty"`
+	Creado    time.Time             `json:"creado"`
+	Terminado *time.Time            `json:"terminado,omitempty"`
+
+	cancelar context.CancelFunc
+}
+
+// terminado indica si el trabajo ya no puede cambiar de estado.
+func (t *Trabajo) terminado() bool {
+	return t.Estado == TrabajoTerminado || t.Estado == TrabajoFallido || t.Estado == TrabajoCancelado
+}
+
+// ejecucion es el cuerpo de un trabajo: entrena con ctx, informa el progreso
+// y devuelve la respuesta del entrenamiento (con la clave "version").
+type ejecucion func(ctx context.Context, progreso algorithms.ProgressFunc) (fiber.Map, error)
+
+// Límites de los trabajos: cuántos entrenan a la vez (por defecto) y
+// cuántos terminados se guardan para consultarlos, y el tamaño máximo de un
+// entrenamiento pedido a POST /softmax/train.
+const (
+	maxTrabajosActivos    = 2
+	maxTrabajosTerminados = 100
+	maxIteraciones        = 20000
+	maxNeuronas           = 256
+)
+
+// errSinCapacidad indica que ya hay maxActivos trabajos sin terminar.
+var errSinCapacidad = errors.New("hay demasiados entrenamientos en curso, intente mas tarde")
+
+// gestorTrabajos guarda los trabajos en memoria; se pierden al reiniciar
+// la API, los modelos que produjeron quedan en el registro. suscriptores
+// son los canales de GET /jobs/:id/eventos de cada trabajo en curso.
+// Como mucho maxActivos trabajos están pendientes o entrenando; de los
+// terminados se guardan los maxTerminados más recientes.
+type gestorTrabajos struct {
+	mu            sync.Mutex
+	trabajos      map[string]*Trabajo
+	suscriptores  map[string][]chan algorithms.Progress
+	ultimo        int
+	maxActivos    int
+	maxTerminados int
+}
+
+func nuevoGestorTrabajos() *gestorTrabajos {
+	return &gestorTrabajos{
+		trabajos:      map[string]*Trabajo{},
+		suscriptores:  map[string][]chan algorithms.Progress{},
+		maxActivos:    maxTrabajosActivos,
+		maxTerminados: maxTrabajosTerminados,
+	}
+}
+
+var trabajosEntrenamiento = nuevoGestorTrabajos()
+
+// configurarTrabajos lee UNMATCH_MAX_TRABAJOS, el número de entrenamientos
+// que pueden correr a la vez.
+func configurarTrabajos() error {
+	valor := os.Getenv("UNMATCH_MAX_TRABAJOS")
+	if valor == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(valor)
+	if err != nil || n <= 0 {
+		return fmt.Errorf("UNMATCH_MAX_TRABAJOS=%q debe ser un entero positivo", valor)
+	}
+	trabajosEntrenamiento.mu.Lock()
+	trabajosEntrenamiento.maxActivos = n
+	trabajosEntrenamiento.mu.Unlock()
+	return nil
+}
+
+// iniciar crea un trabajo y lo ejecuta en una goroutine. Devuelve una copia
+// del trabajo recién creado, o errSinCapacidad si ya hay maxActivos
+// trabajos sin terminar.
+func (g *gestorTrabajos) iniciar(modelo string, ejecutar ejecucion) (Trabajo, error) {
+	g.mu.Lock()
+	activos := 0
+	for _, t := range g.trabajos {
+		if !t.terminado() {
+			activos++
+		}
+	}
+	if activos >= g.maxActivos {
+		g.mu.Unlock()
+		return Trabajo{}, errSinCapacidad
+	}
+
+	ctx, cancelar := context.WithCancel(context.Background())
+	g.ultimo++
+	t := &Trabajo{
+		ID:       fmt.Sprintf("job-%04d", g.ultimo),
+		Modelo:   modelo,
+		Estado:   TrabajoPendiente,
+		Creado:   time.Now().UTC(),
+		cancelar: cancelar,
+	}
+	g.trabajos[t.ID] = t
+	copia := *t
+	g.mu.Unlock()
+
+	go g.correr(ctx, t, ejecutar)
+	return copia, nil
+}
+
+// podar descarta los trabajos terminados más antiguos hasta dejar
+// maxTerminados; se llama con g.mu tomado.
+func (g *gestorTrabajos) podar() {
+	var terminados []*Trabajo
+	for _, t := range g.trabajos {
+		if t.terminado() {
+			terminados = append(terminados, t)
+		}
+	}
+	if len(terminados) <= g.maxTerminados {
+		return
+	}
+	sort.Slice(terminados, func(i, j int) bool { return terminados[i].Terminado.Before(*terminados[j].Terminado) })
+	for _, t := range terminados[:len(terminados)-g.maxTerminados] {
+		delete(g.trabajos, t.ID)
+	}
+}
+
+func (g *gestorTrabajos) correr(ctx context.Context, t *Trabajo, ejecutar ejecucion) {
+	defer t.cancelar()
+
+	g.mu.Lock()
+	if t.Estado == TrabajoCancelado {
+		g.mu.Unlock()
+		return
+	}
+	t.Estado = TrabajoEntrenando
+	g.mu.Unlock()
+
+	resultado, err := ejecutar(ctx, func(p algorithms.Progress) {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		t.Iteracion, t.NIter, t.Loss = p.Iteration, p.NIter, p.Loss
+		t.Curva = append(t.Curva, p)
+		for _, ch := range g.suscriptores[t.ID] {
+			// un cliente lento pierde puntos en lugar de frenar el entrenamiento
+			select {
+			case ch <- p:
+			default:
+			}
+		}
+	})
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer g.podar()
+	defer g.cerrarSuscriptores(t.ID)
+	ahora := time.Now().UTC()
+	t.Terminado = &ahora
+	switch {
+	case errors.Is(err, context.Canceled):
+		t.Estado = TrabajoCancelado
+	case err != nil:
+		t.Estado = TrabajoFallido
+		t.Error = err.Error()
+	default:
+		t.Estado = TrabajoTerminado
+		t.Resultado = resultado
+		if v, ok := resultado["version"].(string); ok {
+			t.Version = v
+		}
+	}
+	fmt.Printf("Trabajo %s: %s\n", t.ID, t.Estado)
+}
+
+// obtener devuelve una copia del trabajo id.
+func (g *gestorTrabajos) obtener(id string) (Trabajo, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, ok := g.trabajos[id]
+	if !ok {
+		return Trabajo{}, false
+	}
+	return *t, true
+}
+
+// listar devuelve una copia de todos los trabajos, del más antiguo al más
+// reciente.
+func (g *gestorTrabajos) listar() []Trabajo {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	lista := make([]Trabajo, 0, len(g.trabajos))
+	for _, t := range g.trabajos {
+		lista = append(lista, *t)
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].ID < lista[j].ID })
+	return lista
+}
+
+// cancelar pide detener el trabajo id. El entrenamiento se detiene al final
+// de la iteración en curso; un trabajo pendiente no llega a empezar.
+func (g *gestorTrabajos) cancelar(id string) (Trabajo, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, ok := g.trabajos[id]
+	if !ok {
+		return Trabajo{}, false
+	}
+	if !t.terminado() {
+		t.cancelar()
+		if t.Estado == TrabajoPendiente {
+			ahora := time.Now().UTC()
+			t.Estado = TrabajoCancelado
+			t.Terminado = &ahora
+			g.cerrarSuscriptores(id)
+			g.podar()
+		}
+	}
+	return *t, true
+}
+
+// suscribir devuelve el estado actual del trabajo id y un canal con cada
+// punto de control posterior. El canal se cierra cuando el trabajo termina;
+// desuscribir debe llamarse siempre al dejar de leer.
+func (g *gestorTrabajos) suscribir(id string) (Trabajo, <-chan algorithms.Progress, func(), bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	t, ok := g.trabajos[id]
+	if !ok {
+		return Trabajo{}, nil, nil, false
+	}
+	ch := make(chan algorithms.Progress, 64)
+	if t.terminado() {
+		close(ch)
+		return *t, ch, func() {}, true
+	}
+	g.suscriptores[id] = append(g.suscriptores[id], ch)
+	desuscribir := func() {
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		canales := g.suscriptores[id]
+		for i, c := range canales {
+			if c == ch {
+				g.suscriptores[id] = append(canales[:i], canales[i+1:]...)
+				break
+			}
+		}
+	}
+	return *t, ch, desuscribir, true
+}
+
+// cerrarSuscriptores cierra los canales del trabajo id; se llama con g.mu
+// tomado.
+func (g *gestorTrabajos) cerrarSuscriptores(id string) {
+	for _, ch := range g.suscriptores[id] {
+		close(ch)
+	}
+	delete(g.suscriptores, id)
+}
+
+// intervaloPing es cada cuánto GET /jobs/:id/eventos manda un comentario
+// para mantener viva la conexión y detectar clientes desconectados.
+const intervaloPing = 15 * time.Second
+
+// eventosTrabajo transmite el progreso de un trabajo como Server-Sent
+// Events: "estado" con el trabajo al conectarse (incluye la curva hasta ese
+// momento), "progreso" con cada punto de control (iteración, loss y
+// métricas de validación) y "fin" con el trabajo terminado.
+func eventosTrabajo(c *fiber.Ctx) error {
+	// copia: Params apunta al buffer de la petición, que fasthttp reutiliza
+	// mientras el stream sigue abierto
+	id := utils.CopyString(c.Params("id"))
+	trabajo, eventos, desuscribir, ok := trabajosEntrenamiento.suscribir(id)
+	if !ok {
+		return c.Status(404).JSON(fiber.Map{"error": "Trabajo no encontrado"})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer desuscribir()
+		if escribirEvento(w, "estado", trabajo) != nil {
+			return
+		}
+		ping := time.NewTicker(intervaloPing)
+		defer ping.Stop()
+		for {
+			select {
+			case p, abierto := <-eventos:
+				if !abierto {
+					final, _ := trabajosEntrenamiento.obtener(id)
+					escribirEvento(w, "fin", final)
+					return
+				}
+				if escribirEvento(w, "progreso", p) != nil {
+					return
+				}
+			case <-ping.C:
+				fmt.Fprint(w, ": ping\n\n")
+				if w.Flush() != nil {
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+// escribirEvento escribe un evento SSE con datos en JSON y lo envía.
+func escribirEvento(w *bufio.Writer, tipo string, datos any) error {
+	bytes, err := json.Marshal(datos)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", tipo, bytes)
+	return w.Flush()
+}
@@ -1,9 +0,0 @@
-package main
-
-import "fmt"
-
-func train() {
-	if err := TrainSoftmaxBronco(); err != nil {
-		fmt.Println("TrainSoftmaxBronco error:", err)
-	}
-}